@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"task-one/auth/dto"
+	"task-one/exception"
+	"task-one/helpers"
+)
+
+type ApiKeyController interface {
+	Create(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
+
+type ApiKeyControllerImpl struct {
+	Service ApiKeyService
+}
+
+func NewApiKeyController(apiKeyService ApiKeyService) ApiKeyController {
+	return &ApiKeyControllerImpl{Service: apiKeyService}
+}
+
+func (controller *ApiKeyControllerImpl) Create(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	apiKeyRequest := &dto.CreateApiKeyDto{}
+	err := helpers.ReadFromRequestBody(request, apiKeyRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	data, err := controller.Service.Create(request.Context(), apiKeyRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	result := helpers.ApiResponse{
+		StatusCode: 201,
+		Data:       data,
+	}
+
+	helpers.WriteToResponse(writer, result, 201)
+}
+
+func (controller *ApiKeyControllerImpl) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	apiKeyId, err := helpers.ParamToInt(params, "id")
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	err = controller.Service.Delete(request.Context(), apiKeyId)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	result := helpers.ApiResponse{
+		StatusCode: 200,
+		Data:       nil,
+	}
+
+	helpers.WriteToResponse(writer, result, 200)
+}
+
+func (controller *ApiKeyControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	data, err := controller.Service.FindAll(request.Context())
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	result := helpers.ApiResponse{
+		StatusCode: 200,
+		Data:       data,
+	}
+
+	helpers.WriteToResponse(writer, result, 200)
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"task-one/auth/model"
+	"task-one/configs/memory"
+	"task-one/exception"
+	"task-one/helpers"
+	"time"
+)
+
+// ApiKeyMemoryRepository keeps API keys in a map. It must be used with
+// transactions of a memory.Store, which guards the map.
+type ApiKeyMemoryRepository struct {
+	apiKeys map[int]model.ApiKey
+	nextId  int
+}
+
+func NewApiKeyMemoryRepository() ApiKeyRepository {
+	return &ApiKeyMemoryRepository{apiKeys: map[int]model.ApiKey{}, nextId: 1}
+}
+
+func (repository *ApiKeyMemoryRepository) Save(ctx context.Context, tx helpers.Tx, apiKey model.ApiKey) (model.ApiKey, error) {
+	if _, err := repository.FindByHash(ctx, tx, apiKey.KeyHash); err == nil {
+		return apiKey, exception.NewConflictError("key_hash already exists")
+	}
+
+	apiKey.Id = repository.nextId
+	apiKey.CreatedAt = time.Now()
+	repository.nextId++
+	repository.put(tx, apiKey)
+	return apiKey, nil
+}
+
+func (repository *ApiKeyMemoryRepository) Delete(ctx context.Context, tx helpers.Tx, apiKeyId int) error {
+	previous, ok := repository.apiKeys[apiKeyId]
+	if !ok {
+		return nil
+	}
+
+	delete(repository.apiKeys, apiKeyId)
+	memory.OnRollback(tx, func() { repository.apiKeys[apiKeyId] = previous })
+	return nil
+}
+
+func (repository *ApiKeyMemoryRepository) FindAll(ctx context.Context, tx helpers.Tx) ([]model.ApiKey, error) {
+	var apiKeys []model.ApiKey
+	for _, apiKey := range repository.apiKeys {
+		apiKeys = append(apiKeys, apiKey)
+	}
+	sort.Slice(apiKeys, func(i, j int) bool { return apiKeys[i].Id < apiKeys[j].Id })
+	return apiKeys, nil
+}
+
+func (repository *ApiKeyMemoryRepository) FindById(ctx context.Context, tx helpers.Tx, apiKeyId int) (model.ApiKey, error) {
+	apiKey, ok := repository.apiKeys[apiKeyId]
+	if !ok {
+		return apiKey, exception.NewNotFoundError("api key Not Found")
+	}
+	return apiKey, nil
+}
+
+func (repository *ApiKeyMemoryRepository) FindByHash(ctx context.Context, tx helpers.Tx, keyHash string) (model.ApiKey, error) {
+	for _, apiKey := range repository.apiKeys {
+		if apiKey.KeyHash == keyHash {
+			return apiKey, nil
+		}
+	}
+	return model.ApiKey{}, exception.NewNotFoundError("api key Not Found")
+}
+
+func (repository *ApiKeyMemoryRepository) UpdateLastUsed(ctx context.Context, tx helpers.Tx, apiKey model.ApiKey) (model.ApiKey, error) {
+	stored, ok := repository.apiKeys[apiKey.Id]
+	if !ok {
+		return apiKey, exception.NewNotFoundError("api key Not Found")
+	}
+
+	lastUsedAt := time.Now()
+	stored.LastUsedAt = &lastUsedAt
+	repository.put(tx, stored)
+	return stored, nil
+}
+
+// put stores apiKey and registers how to restore what it replaced.
+func (repository *ApiKeyMemoryRepository) put(tx helpers.Tx, apiKey model.ApiKey) {
+	previous, existed := repository.apiKeys[apiKey.Id]
+	repository.apiKeys[apiKey.Id] = apiKey
+	memory.OnRollback(tx, func() {
+		if existed {
+			repository.apiKeys[apiKey.Id] = previous
+		} else {
+			delete(repository.apiKeys, apiKey.Id)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"task-one/auth/model"
+	"task-one/configs/database"
+	"task-one/configs/tracing"
+	"task-one/exception"
+	"task-one/helpers"
+	"time"
+)
+
+type ApiKeyRepository interface {
+	Save(ctx context.Context, tx helpers.Tx, apiKey model.ApiKey) (model.ApiKey, error)
+	Delete(ctx context.Context, tx helpers.Tx, apiKeyId int) error
+	FindAll(ctx context.Context, tx helpers.Tx) ([]model.ApiKey, error)
+	FindById(ctx context.Context, tx helpers.Tx, apiKeyId int) (model.ApiKey, error)
+	FindByHash(ctx context.Context, tx helpers.Tx, keyHash string) (model.ApiKey, error)
+	UpdateLastUsed(ctx context.Context, tx helpers.Tx, apiKey model.ApiKey) (model.ApiKey, error)
+}
+
+type ApiKeyRepositoryImpl struct {
+	Dialect database.Dialect
+}
+
+func NewApiKeyRepository(dialect database.Dialect) ApiKeyRepository {
+	return &ApiKeyRepositoryImpl{Dialect: dialect}
+}
+
+func (repository *ApiKeyRepositoryImpl) Save(ctx context.Context, tx helpers.Tx, apiKey model.ApiKey) (model.ApiKey, error) {
+	query := "INSERT INTO api_keys(name, prefix, key_hash, scopes, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at"
+	row := tracing.QueryRowContext(ctx, tx, query, apiKey.Name, apiKey.Prefix, apiKey.KeyHash, repository.Dialect.Array(&apiKey.Scopes), apiKey.ExpiresAt)
+	err := row.Scan(&apiKey.Id, &apiKey.CreatedAt)
+
+	return apiKey, err
+}
+
+func (repository *ApiKeyRepositoryImpl) Delete(ctx context.Context, tx helpers.Tx, apiKeyId int) error {
+	query := "DELETE FROM api_keys WHERE id = $1"
+	_, err := tracing.ExecContext(ctx, tx, query, apiKeyId)
+	return err
+}
+
+func (repository *ApiKeyRepositoryImpl) FindAll(ctx context.Context, tx helpers.Tx) ([]model.ApiKey, error) {
+	query := "SELECT id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at FROM api_keys ORDER BY id"
+	rows, err := tracing.QueryContext(ctx, tx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var apiKeys []model.ApiKey
+	for rows.Next() {
+		apiKey, err := repository.scanApiKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	return apiKeys, rows.Err()
+}
+
+func (repository *ApiKeyRepositoryImpl) FindById(ctx context.Context, tx helpers.Tx, apiKeyId int) (model.ApiKey, error) {
+	query := "SELECT id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at FROM api_keys WHERE id = $1"
+	return repository.findOne(ctx, tx, query, apiKeyId)
+}
+
+func (repository *ApiKeyRepositoryImpl) FindByHash(ctx context.Context, tx helpers.Tx, keyHash string) (model.ApiKey, error) {
+	query := "SELECT id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at FROM api_keys WHERE key_hash = $1"
+	return repository.findOne(ctx, tx, query, keyHash)
+}
+
+func (repository *ApiKeyRepositoryImpl) UpdateLastUsed(ctx context.Context, tx helpers.Tx, apiKey model.ApiKey) (model.ApiKey, error) {
+	query := "UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING last_used_at"
+	var lastUsedAt time.Time
+	err := tracing.QueryRowContext(ctx, tx, query, apiKey.Id).Scan(&lastUsedAt)
+	if err != nil {
+		return apiKey, err
+	}
+
+	apiKey.LastUsedAt = &lastUsedAt
+	return apiKey, nil
+}
+
+func (repository *ApiKeyRepositoryImpl) findOne(ctx context.Context, tx helpers.Tx, query string, arg interface{}) (model.ApiKey, error) {
+	rows, err := tracing.QueryContext(ctx, tx, query, arg)
+	if err != nil {
+		return model.ApiKey{}, err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return repository.scanApiKey(rows)
+	} else {
+		return model.ApiKey{}, exception.NewNotFoundError("api key Not Found")
+	}
+}
+
+func (repository *ApiKeyRepositoryImpl) scanApiKey(rows *sql.Rows) (model.ApiKey, error) {
+	apiKey := model.ApiKey{}
+	var lastUsedAt sql.NullTime
+	err := rows.Scan(&apiKey.Id, &apiKey.Name, &apiKey.Prefix, &apiKey.KeyHash, repository.Dialect.Array(&apiKey.Scopes), &apiKey.ExpiresAt, &lastUsedAt, &apiKey.CreatedAt)
+
+	if lastUsedAt.Valid {
+		apiKey.LastUsedAt = &lastUsedAt.Time
+	}
+	return apiKey, err
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"task-one/auth/dto"
+	"task-one/auth/model"
+	"task-one/auth/response"
+	"task-one/exception"
+	"task-one/helpers"
+	"time"
+)
+
+const apiKeyPrefix = "tk_"
+
+type ApiKeyService interface {
+	Create(ctx context.Context, request *dto.CreateApiKeyDto) (response.CreatedApiKeyResponse, error)
+	Delete(ctx context.Context, apiKeyId int) error
+	FindAll(ctx context.Context) ([]response.ApiKeyResponse, error)
+	Authenticate(ctx context.Context, key string) (model.ApiKey, error)
+}
+
+type ApiKeyServiceImpl struct {
+	Repository ApiKeyRepository
+	TxManager  helpers.TxManager
+	Logger     *slog.Logger
+}
+
+func NewApiKeyService(repository ApiKeyRepository, txManager helpers.TxManager, logger *slog.Logger) ApiKeyService {
+	return &ApiKeyServiceImpl{Repository: repository, TxManager: txManager, Logger: logger}
+}
+
+func (service *ApiKeyServiceImpl) Create(ctx context.Context, request *dto.CreateApiKeyDto) (createdResponse response.CreatedApiKeyResponse, err error) {
+	for _, scope := range request.Scopes {
+		if !IsValidScope(scope) {
+			return createdResponse, exception.NewBadRequestError("unknown scope " + scope)
+		}
+	}
+
+	secret := make([]byte, 24)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return createdResponse, err
+	}
+
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return createdResponse, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
+
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	apiKey, err := service.Repository.Save(ctx, tx, model.ApiKey{
+		Name:      request.Name,
+		Prefix:    key[:len(apiKeyPrefix)+8],
+		KeyHash:   hashApiKey(key),
+		Scopes:    request.Scopes,
+		ExpiresAt: request.ExpiresAt,
+	})
+	if err != nil {
+		return createdResponse, err
+	}
+
+	service.Logger.InfoContext(ctx, "api key created", slog.Int("api_key_id", apiKey.Id), slog.String("prefix", apiKey.Prefix))
+	return response.CreatedApiKeyResponse{
+		ApiKeyResponse: model.ToApiKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+func (service *ApiKeyServiceImpl) Delete(ctx context.Context, apiKeyId int) (err error) {
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
+
+	apiKey, err := service.Repository.FindById(ctx, tx, apiKeyId)
+	if err != nil {
+		return err
+	}
+
+	err = service.Repository.Delete(ctx, tx, apiKey.Id)
+	if err != nil {
+		return err
+	}
+
+	service.Logger.InfoContext(ctx, "api key deleted", slog.Int("api_key_id", apiKey.Id))
+	return nil
+}
+
+func (service *ApiKeyServiceImpl) FindAll(ctx context.Context) (apiKeyResponses []response.ApiKeyResponse, err error) {
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
+
+	apiKeys, err := service.Repository.FindAll(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToApiKeyResponses(apiKeys), nil
+}
+
+// Authenticate looks up the key by its hash, rejects it once expired and
+// records the time it was last used. Unknown and expired keys are reported as
+// exception.UnauthorizedError.
+func (service *ApiKeyServiceImpl) Authenticate(ctx context.Context, key string) (apiKey model.ApiKey, err error) {
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return apiKey, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
+
+	apiKey, err = service.Repository.FindByHash(ctx, tx, hashApiKey(key))
+	if errors.As(err, &exception.NotFoundError{}) {
+		return apiKey, exception.NewUnauthorizedError("invalid api key")
+	}
+	if err != nil {
+		return apiKey, err
+	}
+
+	if !apiKey.ExpiresAt.After(time.Now()) {
+		return apiKey, exception.NewUnauthorizedError("api key expired")
+	}
+
+	return service.Repository.UpdateLastUsed(ctx, tx, apiKey)
+}
+
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
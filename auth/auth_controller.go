@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"task-one/auth/dto"
+	"task-one/exception"
+	"task-one/helpers"
+)
+
+type AuthController interface {
+	Register(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Login(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Refresh(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
+
+type AuthControllerImpl struct {
+	Service AuthService
+}
+
+func NewAuthController(authService AuthService) AuthController {
+	return &AuthControllerImpl{Service: authService}
+}
+
+func (controller *AuthControllerImpl) Register(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	registerRequest := &dto.RegisterDto{}
+	err := helpers.ReadFromRequestBody(request, registerRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	data, err := controller.Service.Register(request.Context(), registerRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	result := helpers.ApiResponse{
+		StatusCode: 201,
+		Data:       data,
+	}
+
+	helpers.WriteToResponse(writer, result, 201)
+}
+
+func (controller *AuthControllerImpl) Login(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	loginRequest := &dto.LoginDto{}
+	err := helpers.ReadFromRequestBody(request, loginRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	data, err := controller.Service.Login(request.Context(), loginRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	result := helpers.ApiResponse{
+		StatusCode: 200,
+		Data:       data,
+	}
+
+	helpers.WriteToResponse(writer, result, 200)
+}
+
+func (controller *AuthControllerImpl) Refresh(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	refreshRequest := &dto.RefreshTokenDto{}
+	err := helpers.ReadFromRequestBody(request, refreshRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	data, err := controller.Service.Refresh(request.Context(), refreshRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	result := helpers.ApiResponse{
+		StatusCode: 200,
+		Data:       data,
+	}
+
+	helpers.WriteToResponse(writer, result, 200)
+}
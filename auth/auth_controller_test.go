@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-playground/assert/v2"
+	"github.com/julienschmidt/httprouter"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"task-one/auth/model"
+	"task-one/configs/ratelimit"
+	"task-one/exception"
+	"task-one/helpers"
+	"task-one/testutil"
+	"testing"
+	"time"
+)
+
+var env = testutil.Config()
+
+var tokens = NewTokenManager(&helpers.JWTConfig{
+	Secret:          "test-secret",
+	AccessTokenTTL:  time.Minute,
+	RefreshTokenTTL: time.Hour,
+})
+
+// testBackend holds the repositories over testutil.Backend.
+type testBackend struct {
+	testutil.Backend
+	Users   UserRepository
+	ApiKeys ApiKeyRepository
+}
+
+func newTestBackend() testBackend {
+	backend := testBackend{Backend: testutil.NewBackend(env.DB, "users", "api_keys")}
+	if backend.InMemory() {
+		backend.Users = NewUserMemoryRepository()
+		backend.ApiKeys = NewApiKeyMemoryRepository()
+	} else {
+		backend.Users = NewUserRepository()
+		backend.ApiKeys = NewApiKeyRepository(backend.Dialect)
+	}
+	return backend
+}
+
+func setupRouter(backend testBackend) http.Handler {
+	router := httprouter.New()
+	router.PanicHandler = exception.ErrorHandler
+	apiKeyService := NewApiKeyService(backend.ApiKeys, backend.TxManager, slog.Default())
+	RegisterRoute(router, backend.TxManager, backend.Users, tokens, apiKeyService, NewAuthMiddleware(tokens, apiKeyService), ratelimit.NewUnlimitedMiddleware(), ratelimit.NewUnlimitedMiddleware(), slog.Default())
+
+	return router
+}
+
+func postJSON(router http.Handler, url string, body string) (*http.Response, map[string]interface{}) {
+	req := httptest.NewRequest("POST", url, strings.NewReader(body))
+	req.Header.Add("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	responseBody, _ := ioutil.ReadAll(res.Body)
+	var result map[string]interface{}
+	json.Unmarshal(responseBody, &result)
+
+	return res, result
+}
+
+func TestRegister(t *testing.T) {
+	router := setupRouter(newTestBackend())
+
+	t.Run("Test Register Success", func(t *testing.T) {
+		res, body := postJSON(router, "http://localhost:3001/auth/register", `{"name":"Fazrul","email":"fazrul@mail.com","password":"secret123"}`)
+
+		assert.Equal(t, 201, res.StatusCode)
+		assert.Equal(t, "fazrul@mail.com", body["data"].(map[string]interface{})["email"])
+	})
+
+	t.Run("Test Register Duplicate Email", func(t *testing.T) {
+		res, _ := postJSON(router, "http://localhost:3001/auth/register", `{"name":"Fazrul","email":"fazrul@mail.com","password":"secret123"}`)
+
+		assert.Equal(t, 409, res.StatusCode)
+	})
+}
+
+func TestLoginAndRefresh(t *testing.T) {
+	router := setupRouter(newTestBackend())
+
+	postJSON(router, "http://localhost:3001/auth/register", `{"name":"Fazrul","email":"fazrul@mail.com","password":"secret123"}`)
+
+	t.Run("Test Login Failed", func(t *testing.T) {
+		res, _ := postJSON(router, "http://localhost:3001/auth/login", `{"email":"fazrul@mail.com","password":"wrong-password"}`)
+
+		assert.Equal(t, 401, res.StatusCode)
+	})
+
+	res, body := postJSON(router, "http://localhost:3001/auth/login", `{"email":"fazrul@mail.com","password":"secret123"}`)
+	assert.Equal(t, 200, res.StatusCode)
+
+	data := body["data"].(map[string]interface{})
+	claims, err := tokens.ParseToken(data["access_token"].(string), AccessToken)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "fazrul@mail.com", claims.Email)
+
+	t.Run("Test Refresh Success", func(t *testing.T) {
+		res, body := postJSON(router, "http://localhost:3001/auth/refresh", `{"refresh_token":"`+data["refresh_token"].(string)+`"}`)
+
+		assert.Equal(t, 200, res.StatusCode)
+		assert.NotEqual(t, "", body["data"].(map[string]interface{})["access_token"])
+	})
+
+	t.Run("Test Refresh With Access Token Failed", func(t *testing.T) {
+		res, _ := postJSON(router, "http://localhost:3001/auth/refresh", `{"refresh_token":"`+data["access_token"].(string)+`"}`)
+
+		assert.Equal(t, 401, res.StatusCode)
+	})
+}
+
+func TestUpdateRole(t *testing.T) {
+	router := setupRouter(newTestBackend())
+
+	_, body := postJSON(router, "http://localhost:3001/auth/register", `{"name":"Fazrul","email":"fazrul@mail.com","password":"secret123"}`)
+	userId := int(body["data"].(map[string]interface{})["id"].(float64))
+	assert.Equal(t, RoleViewer, body["data"].(map[string]interface{})["role"])
+
+	updateRole := func(role string) *http.Response {
+		token, _ := tokens.GenerateAccessToken(model.User{Id: 99, Email: "root@mail.com", Role: role})
+		req := httptest.NewRequest("PUT", "http://localhost:3001/admin/users/"+strconv.Itoa(userId)+"/role", strings.NewReader(`{"role":"editor"}`))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Authorization", "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Result()
+	}
+
+	t.Run("Test Update Role As Viewer Forbidden", func(t *testing.T) {
+		assert.Equal(t, 403, updateRole(RoleViewer).StatusCode)
+	})
+
+	t.Run("Test Update Role As Admin Success", func(t *testing.T) {
+		assert.Equal(t, 200, updateRole(RoleAdmin).StatusCode)
+
+		_, body := postJSON(router, "http://localhost:3001/auth/login", `{"email":"fazrul@mail.com","password":"secret123"}`)
+		claims, _ := tokens.ParseToken(body["data"].(map[string]interface{})["access_token"].(string), AccessToken)
+		assert.Equal(t, RoleEditor, claims.Role)
+	})
+}
+
+func TestApiKey(t *testing.T) {
+	router := setupRouter(newTestBackend())
+
+	adminToken, _ := tokens.GenerateAccessToken(model.User{Id: 99, Email: "root@mail.com", Role: RoleAdmin})
+	reqBody := `{"name":"exporter","scopes":["products:write"],"expires_at":"` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`
+	req := httptest.NewRequest("POST", "http://localhost:3001/admin/api-keys", strings.NewReader(reqBody))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer "+adminToken)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+
+	assert.Equal(t, 201, res.StatusCode)
+	key := responseBody["data"].(map[string]interface{})["key"].(string)
+
+	listApiKeys := func(authorization string) *http.Response {
+		req := httptest.NewRequest("GET", "http://localhost:3001/admin/api-keys", nil)
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Authorization", authorization)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Result()
+	}
+
+	t.Run("Test Api Key Without Scope Forbidden", func(t *testing.T) {
+		assert.Equal(t, 403, listApiKeys("ApiKey "+key).StatusCode)
+	})
+
+	t.Run("Test Invalid Api Key Unauthorized", func(t *testing.T) {
+		assert.Equal(t, 401, listApiKeys("ApiKey tk_invalid").StatusCode)
+	})
+}
+
+// oneRequest allows a single request per key.
+type oneRequest struct {
+	seen map[string]bool
+}
+
+func (limiter *oneRequest) Allow(ctx context.Context, key string, limit helpers.RateLimit) (ratelimit.Result, error) {
+	allowed := !limiter.seen[key]
+	limiter.seen[key] = true
+	return ratelimit.Result{Allowed: allowed, Limit: 1}, nil
+}
+
+func TestRateLimitCountsUnauthenticatedRequests(t *testing.T) {
+	backend := newTestBackend()
+	router := httprouter.New()
+	apiKeyService := NewApiKeyService(backend.ApiKeys, backend.TxManager, slog.Default())
+	limiter := ratelimit.NewMiddleware(&oneRequest{seen: map[string]bool{}}, &helpers.RateLimitConfig{
+		Enabled: true,
+		Default: helpers.RateLimit{Requests: 1, Window: time.Minute},
+	}, ClientKey, slog.Default())
+	RegisterRoute(router, backend.TxManager, backend.Users, tokens, apiKeyService, NewAuthMiddleware(tokens, apiKeyService), limiter, ratelimit.NewUnlimitedMiddleware(), slog.Default())
+
+	var statuses []int
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/admin/users", nil))
+		statuses = append(statuses, recorder.Code)
+	}
+
+	assert.Equal(t, []int{http.StatusUnauthorized, http.StatusTooManyRequests}, statuses)
+}
+
+func TestRateLimitCountsEachUserAcrossAddresses(t *testing.T) {
+	backend := newTestBackend()
+	router := httprouter.New()
+	apiKeyService := NewApiKeyService(backend.ApiKeys, backend.TxManager, slog.Default())
+	principalLimiter := ratelimit.NewMiddleware(&oneRequest{seen: map[string]bool{}}, &helpers.RateLimitConfig{
+		Enabled: true,
+		Default: helpers.RateLimit{Requests: 1, Window: time.Minute},
+	}, PrincipalKey, slog.Default())
+	RegisterRoute(router, backend.TxManager, backend.Users, tokens, apiKeyService, NewAuthMiddleware(tokens, apiKeyService), ratelimit.NewUnlimitedMiddleware(), principalLimiter, slog.Default())
+
+	get := func(userId int, remoteAddr string) int {
+		token, _ := tokens.GenerateAccessToken(model.User{Id: userId, Email: "root@mail.com", Role: RoleAdmin})
+		req := httptest.NewRequest("GET", "/admin/users", nil)
+		req.Header.Add("Authorization", "Bearer "+token)
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get(99, "10.0.0.1:1234"))
+	assert.Equal(t, http.StatusTooManyRequests, get(99, "10.0.0.2:1234"))
+	assert.Equal(t, http.StatusOK, get(98, "10.0.0.2:1234"))
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"github.com/julienschmidt/httprouter"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"task-one/exception"
+)
+
+type contextKey string
+
+type AuthMiddleware interface {
+	Protect(handle httprouter.Handle) httprouter.Handle
+	Authorize(permission string, handle httprouter.Handle) httprouter.Handle
+}
+
+type AuthMiddlewareImpl struct {
+	Tokens  TokenManager
+	ApiKeys ApiKeyService
+}
+
+func NewAuthMiddleware(tokens TokenManager, apiKeys ApiKeyService) AuthMiddleware {
+	return &AuthMiddlewareImpl{Tokens: tokens, ApiKeys: apiKeys}
+}
+
+// Protect rejects the request with 401 unless its Authorization header carries
+// either a valid access token ("Bearer <token>") or a valid API key
+// ("ApiKey <key>"). The authenticated caller is stored in the request context
+// and can be read back with PrincipalFromContext.
+func (middleware *AuthMiddlewareImpl) Protect(handle httprouter.Handle) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		principal, err := middleware.authenticate(request)
+		if err != nil {
+			exception.ErrorHandler(writer, request, err)
+			return
+		}
+
+		ctx := context.WithValue(request.Context(), principalContextKey, principal)
+		handle(writer, request.WithContext(ctx), params)
+	}
+}
+
+// Authorize wraps Protect and additionally rejects the request with 403 unless
+// the authenticated caller holds the given permission.
+func (middleware *AuthMiddlewareImpl) Authorize(permission string, handle httprouter.Handle) httprouter.Handle {
+	return middleware.Protect(func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		principal, _ := PrincipalFromContext(request.Context())
+		if !principal.Can(permission) {
+			exception.ErrorHandler(writer, request, exception.NewForbiddenError("missing permission "+permission))
+			return
+		}
+
+		handle(writer, request, params)
+	})
+}
+
+// ClientKey names the caller for rate limiting by its remote IP. This limit
+// runs before authentication, so that requests with bad credentials are
+// counted too. X-Forwarded-For is not trusted, as any client could set it.
+func ClientKey(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		host = request.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// PrincipalKey names the caller for rate limiting by the user or API key it
+// authenticated as, so that spreading requests over several IPs does not
+// raise its limit. It is meant for limits inside Protect, and falls back to
+// ClientKey elsewhere.
+func PrincipalKey(request *http.Request) string {
+	principal, ok := PrincipalFromContext(request.Context())
+	if !ok {
+		return ClientKey(request)
+	}
+	if principal.IsApiKey() {
+		return "key:" + strconv.Itoa(principal.ApiKeyId)
+	}
+	return "user:" + strconv.Itoa(principal.UserId)
+}
+
+func (middleware *AuthMiddlewareImpl) authenticate(request *http.Request) (*Principal, error) {
+	header := request.Header.Get("Authorization")
+
+	if strings.HasPrefix(header, "Bearer ") {
+		claims, err := middleware.Tokens.ParseToken(strings.TrimPrefix(header, "Bearer "), AccessToken)
+		if err != nil {
+			return nil, exception.NewUnauthorizedError("invalid access token")
+		}
+
+		return &Principal{
+			UserId: claims.UserId(),
+			Email:  claims.Email,
+			Role:   claims.Role,
+		}, nil
+	}
+
+	if strings.HasPrefix(header, "ApiKey ") {
+		apiKey, err := middleware.ApiKeys.Authenticate(request.Context(), strings.TrimPrefix(header, "ApiKey "))
+		if err != nil {
+			return nil, err
+		}
+
+		return &Principal{
+			ApiKeyId: apiKey.Id,
+			Scopes:   apiKey.Scopes,
+		}, nil
+	}
+
+	return nil, exception.NewUnauthorizedError("missing bearer token or api key")
+}
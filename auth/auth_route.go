@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"log/slog"
+	"task-one/configs/ratelimit"
+	"task-one/helpers"
+)
+
+func RegisterRoute(router *httprouter.Router, txManager helpers.TxManager, userRepository UserRepository, tokens TokenManager, apiKeyService ApiKeyService, authMiddleware AuthMiddleware, limiter ratelimit.Middleware, principalLimiter ratelimit.Middleware, logger *slog.Logger) {
+
+	authService := NewAuthService(userRepository, txManager, tokens, logger)
+	authController := NewAuthController(authService)
+	userService := NewUserService(userRepository, txManager, logger)
+	userController := NewUserController(userService)
+	apiKeyController := NewApiKeyController(apiKeyService)
+
+	router.POST("/auth/register", limiter.Limit("POST /auth/register", authController.Register))
+	router.POST("/auth/login", limiter.Limit("POST /auth/login", authController.Login))
+	router.POST("/auth/refresh", limiter.Limit("POST /auth/refresh", authController.Refresh))
+
+	router.GET("/admin/users", limiter.Limit("GET /admin/users", authMiddleware.Authorize(UsersManage, principalLimiter.Limit("GET /admin/users", userController.FindAll))))
+	router.PUT("/admin/users/:id/role", limiter.Limit("PUT /admin/users/:id/role", authMiddleware.Authorize(UsersManage, principalLimiter.Limit("PUT /admin/users/:id/role", userController.UpdateRole))))
+
+	router.POST("/admin/api-keys", limiter.Limit("POST /admin/api-keys", authMiddleware.Authorize(ApiKeysManage, principalLimiter.Limit("POST /admin/api-keys", apiKeyController.Create))))
+	router.GET("/admin/api-keys", limiter.Limit("GET /admin/api-keys", authMiddleware.Authorize(ApiKeysManage, principalLimiter.Limit("GET /admin/api-keys", apiKeyController.FindAll))))
+	router.DELETE("/admin/api-keys/:id", limiter.Limit("DELETE /admin/api-keys/:id", authMiddleware.Authorize(ApiKeysManage, principalLimiter.Limit("DELETE /admin/api-keys/:id", apiKeyController.Delete))))
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"log/slog"
+	"strings"
+	"task-one/auth/dto"
+	"task-one/auth/model"
+	"task-one/auth/response"
+	"task-one/exception"
+	"task-one/helpers"
+)
+
+type AuthService interface {
+	Register(ctx context.Context, request *dto.RegisterDto) (response.UserResponse, error)
+	Login(ctx context.Context, request *dto.LoginDto) (response.TokenResponse, error)
+	Refresh(ctx context.Context, request *dto.RefreshTokenDto) (response.TokenResponse, error)
+}
+
+type AuthServiceImpl struct {
+	Repository UserRepository
+	TxManager  helpers.TxManager
+	Tokens     TokenManager
+	Logger     *slog.Logger
+}
+
+func NewAuthService(repository UserRepository, txManager helpers.TxManager, tokens TokenManager, logger *slog.Logger) AuthService {
+	return &AuthServiceImpl{Repository: repository, TxManager: txManager, Tokens: tokens, Logger: logger}
+}
+
+func (service *AuthServiceImpl) Register(ctx context.Context, request *dto.RegisterDto) (userResponse response.UserResponse, err error) {
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return userResponse, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
+
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	_, err = service.Repository.FindByEmail(ctx, tx, email)
+	if err == nil {
+		return userResponse, exception.NewConflictError("email already registered")
+	}
+	if !errors.As(err, &exception.NotFoundError{}) {
+		return userResponse, err
+	}
+
+	password, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return userResponse, err
+	}
+
+	user, err := service.Repository.Save(ctx, tx, model.User{
+		Name:     request.Name,
+		Email:    email,
+		Password: string(password),
+		Role:     RoleViewer,
+	})
+	if err != nil {
+		return userResponse, err
+	}
+
+	service.Logger.InfoContext(ctx, "user registered", slog.Int("user_id", user.Id))
+	return model.ToUserResponse(user), nil
+}
+
+func (service *AuthServiceImpl) Login(ctx context.Context, request *dto.LoginDto) (tokenResponse response.TokenResponse, err error) {
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return tokenResponse, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
+
+	user, err := service.Repository.FindByEmail(ctx, tx, strings.ToLower(strings.TrimSpace(request.Email)))
+	if errors.As(err, &exception.NotFoundError{}) {
+		return tokenResponse, exception.NewUnauthorizedError("invalid email or password")
+	}
+	if err != nil {
+		return tokenResponse, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password))
+	if err != nil {
+		service.Logger.WarnContext(ctx, "login with wrong password", slog.Int("user_id", user.Id))
+		return tokenResponse, exception.NewUnauthorizedError("invalid email or password")
+	}
+
+	return service.issueTokens(user)
+}
+
+func (service *AuthServiceImpl) Refresh(ctx context.Context, request *dto.RefreshTokenDto) (tokenResponse response.TokenResponse, err error) {
+	claims, err := service.Tokens.ParseToken(request.RefreshToken, RefreshToken)
+	if err != nil {
+		return tokenResponse, exception.NewUnauthorizedError("invalid refresh token")
+	}
+
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return tokenResponse, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
+
+	user, err := service.Repository.FindById(ctx, tx, claims.UserId())
+	if errors.As(err, &exception.NotFoundError{}) {
+		return tokenResponse, exception.NewUnauthorizedError("invalid refresh token")
+	}
+	if err != nil {
+		return tokenResponse, err
+	}
+
+	return service.issueTokens(user)
+}
+
+func (service *AuthServiceImpl) issueTokens(user model.User) (response.TokenResponse, error) {
+	accessToken, err := service.Tokens.GenerateAccessToken(user)
+	if err != nil {
+		return response.TokenResponse{}, err
+	}
+
+	refreshToken, err := service.Tokens.GenerateRefreshToken(user)
+	if err != nil {
+		return response.TokenResponse{}, err
+	}
+
+	return response.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(service.Tokens.AccessTokenTTL().Seconds()),
+	}, nil
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+type CreateApiKeyDto struct {
+	Name      string    `json:"name" validate:"required"`
+	Scopes    []string  `json:"scopes" validate:"required,min=1,dive,oneof=categories:write products:write"`
+	ExpiresAt time.Time `json:"expires_at" validate:"required"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+type RegisterDto struct {
+	Name     string `json:"name" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type UpdateRoleDto struct {
+	UserId int
+	Role   string `json:"role" validate:"required,oneof=admin editor viewer"`
+}
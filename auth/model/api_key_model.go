@@ -0,0 +1,37 @@
+package model
+
+import (
+	"task-one/auth/response"
+	"time"
+)
+
+type ApiKey struct {
+	Id         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func ToApiKeyResponse(apiKey ApiKey) response.ApiKeyResponse {
+	return response.ApiKeyResponse{
+		Id:         apiKey.Id,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+func ToApiKeyResponses(apiKeys []ApiKey) []response.ApiKeyResponse {
+	var apiKeyResponses []response.ApiKeyResponse
+	for _, apiKey := range apiKeys {
+		apiKeyResponses = append(apiKeyResponses, ToApiKeyResponse(apiKey))
+	}
+	return apiKeyResponses
+}
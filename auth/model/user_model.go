@@ -0,0 +1,28 @@
+package model
+
+import "task-one/auth/response"
+
+type User struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"-"`
+	Role     string `json:"role"`
+}
+
+func ToUserResponses(users []User) []response.UserResponse {
+	var userResponses []response.UserResponse
+	for _, user := range users {
+		userResponses = append(userResponses, ToUserResponse(user))
+	}
+	return userResponses
+}
+
+func ToUserResponse(user User) response.UserResponse {
+	return response.UserResponse{
+		Id:    user.Id,
+		Name:  user.Name,
+		Email: user.Email,
+		Role:  user.Role,
+	}
+}
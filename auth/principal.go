@@ -0,0 +1,39 @@
+package auth
+
+import "context"
+
+// Principal is the caller a request was authenticated as, either a user
+// holding a JWT access token or a machine client holding an API key.
+type Principal struct {
+	UserId   int
+	ApiKeyId int
+	Email    string
+	Role     string
+	Scopes   []string
+}
+
+func (principal *Principal) IsApiKey() bool {
+	return principal.ApiKeyId != 0
+}
+
+// Can reports whether the principal holds the permission, through its role
+// for users or through its scopes for API keys.
+func (principal *Principal) Can(permission string) bool {
+	if principal.IsApiKey() {
+		for _, scope := range principal.Scopes {
+			if scope == permission {
+				return true
+			}
+		}
+		return false
+	}
+
+	return RoleHasPermission(principal.Role, permission)
+}
+
+const principalContextKey contextKey = "auth.principal"
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey).(*Principal)
+	return principal, ok
+}
@@ -0,0 +1,20 @@
+package response
+
+import "time"
+
+type ApiKeyResponse struct {
+	Id         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedApiKeyResponse is only returned once, when the key is created. The
+// plain key is not stored and cannot be retrieved afterwards.
+type CreatedApiKeyResponse struct {
+	ApiKeyResponse
+	Key string `json:"key"`
+}
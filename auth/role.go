@@ -0,0 +1,49 @@
+package auth
+
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+const (
+	CategoriesWrite = "categories:write"
+	ProductsWrite   = "products:write"
+	UsersManage     = "users:manage"
+	ApiKeysManage   = "api-keys:manage"
+)
+
+// rolePermissions only lists what needs a permission. Reads are public, so a
+// viewer holds none.
+var rolePermissions = map[string][]string{
+	RoleAdmin:  {CategoriesWrite, ProductsWrite, UsersManage, ApiKeysManage},
+	RoleEditor: {CategoriesWrite, ProductsWrite},
+	RoleViewer: {},
+}
+
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+func RoleHasPermission(role string, permission string) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKeyScopes lists the permissions that may be granted to an API key.
+// Managing users and keys stays reserved to admins.
+var apiKeyScopes = []string{CategoriesWrite, ProductsWrite}
+
+func IsValidScope(scope string) bool {
+	for _, allowed := range apiKeyScopes {
+		if allowed == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"strconv"
+	"task-one/auth/model"
+	"task-one/helpers"
+	"time"
+)
+
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
+type Claims struct {
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	TokenType string `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// UserId returns the id of the user the token was issued for.
+func (claims *Claims) UserId() int {
+	id, _ := strconv.Atoi(claims.Subject)
+	return id
+}
+
+type TokenManager interface {
+	GenerateAccessToken(user model.User) (string, error)
+	GenerateRefreshToken(user model.User) (string, error)
+	ParseToken(token string, tokenType string) (*Claims, error)
+	AccessTokenTTL() time.Duration
+}
+
+type TokenManagerImpl struct {
+	secret          []byte
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+}
+
+func NewTokenManager(config *helpers.JWTConfig) TokenManager {
+	if config.Secret == "" {
+		panic(errors.New("JWT_SECRET is not set"))
+	}
+
+	return &TokenManagerImpl{
+		secret:          []byte(config.Secret),
+		accessTokenTTL:  config.AccessTokenTTL,
+		refreshTokenTTL: config.RefreshTokenTTL,
+	}
+}
+
+func (manager *TokenManagerImpl) GenerateAccessToken(user model.User) (string, error) {
+	return manager.generate(user, AccessToken, manager.accessTokenTTL)
+}
+
+func (manager *TokenManagerImpl) GenerateRefreshToken(user model.User) (string, error) {
+	return manager.generate(user, RefreshToken, manager.refreshTokenTTL)
+}
+
+func (manager *TokenManagerImpl) AccessTokenTTL() time.Duration {
+	return manager.accessTokenTTL
+}
+
+func (manager *TokenManagerImpl) ParseToken(token string, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		return manager.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != tokenType {
+		return nil, errors.New("unexpected token type")
+	}
+
+	return claims, nil
+}
+
+func (manager *TokenManagerImpl) generate(user model.User, tokenType string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Email:     user.Email,
+		Role:      user.Role,
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(user.Id),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(manager.secret)
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"task-one/auth/dto"
+	"task-one/exception"
+	"task-one/helpers"
+)
+
+type UserController interface {
+	FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	UpdateRole(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
+
+type UserControllerImpl struct {
+	Service UserService
+}
+
+func NewUserController(userService UserService) UserController {
+	return &UserControllerImpl{Service: userService}
+}
+
+func (controller *UserControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	data, err := controller.Service.FindAll(request.Context())
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	result := helpers.ApiResponse{
+		StatusCode: 200,
+		Data:       data,
+	}
+
+	helpers.WriteToResponse(writer, result, 200)
+}
+
+func (controller *UserControllerImpl) UpdateRole(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	updateRoleRequest := &dto.UpdateRoleDto{}
+	err := helpers.ReadFromRequestBody(request, updateRoleRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	userId, err := helpers.ParamToInt(params, "id")
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	updateRoleRequest.UserId = userId
+
+	data, err := controller.Service.UpdateRole(request.Context(), updateRoleRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	result := helpers.ApiResponse{
+		StatusCode: 200,
+		Data:       data,
+	}
+
+	helpers.WriteToResponse(writer, result, 200)
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"task-one/auth/model"
+	"task-one/configs/memory"
+	"task-one/exception"
+	"task-one/helpers"
+)
+
+// UserMemoryRepository keeps users in a map. It must be used with transactions
+// of a memory.Store, which guards the map.
+type UserMemoryRepository struct {
+	users  map[int]model.User
+	nextId int
+}
+
+func NewUserMemoryRepository() UserRepository {
+	return &UserMemoryRepository{users: map[int]model.User{}, nextId: 1}
+}
+
+func (repository *UserMemoryRepository) Save(ctx context.Context, tx helpers.Tx, user model.User) (model.User, error) {
+	if _, err := repository.FindByEmail(ctx, tx, user.Email); err == nil {
+		return user, exception.NewConflictError("email already exists")
+	}
+
+	user.Id = repository.nextId
+	repository.nextId++
+	repository.put(tx, user)
+	return user, nil
+}
+
+func (repository *UserMemoryRepository) FindByEmail(ctx context.Context, tx helpers.Tx, email string) (model.User, error) {
+	for _, user := range repository.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return model.User{}, exception.NewNotFoundError("user Not Found")
+}
+
+func (repository *UserMemoryRepository) FindById(ctx context.Context, tx helpers.Tx, userId int) (model.User, error) {
+	user, ok := repository.users[userId]
+	if !ok {
+		return user, exception.NewNotFoundError("user Not Found")
+	}
+	return user, nil
+}
+
+func (repository *UserMemoryRepository) FindAll(ctx context.Context, tx helpers.Tx) ([]model.User, error) {
+	var users []model.User
+	for _, user := range repository.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Id < users[j].Id })
+	return users, nil
+}
+
+func (repository *UserMemoryRepository) UpdateRole(ctx context.Context, tx helpers.Tx, user model.User) (model.User, error) {
+	stored, ok := repository.users[user.Id]
+	if ok {
+		stored.Role = user.Role
+		repository.put(tx, stored)
+	}
+	return user, nil
+}
+
+// put stores user and registers how to restore what it replaced.
+func (repository *UserMemoryRepository) put(tx helpers.Tx, user model.User) {
+	previous, existed := repository.users[user.Id]
+	repository.users[user.Id] = user
+	memory.OnRollback(tx, func() {
+		if existed {
+			repository.users[user.Id] = previous
+		} else {
+			delete(repository.users, user.Id)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"task-one/auth/model"
+	"task-one/configs/tracing"
+	"task-one/exception"
+	"task-one/helpers"
+)
+
+type UserRepository interface {
+	Save(ctx context.Context, tx helpers.Tx, user model.User) (model.User, error)
+	FindByEmail(ctx context.Context, tx helpers.Tx, email string) (model.User, error)
+	FindById(ctx context.Context, tx helpers.Tx, userId int) (model.User, error)
+	FindAll(ctx context.Context, tx helpers.Tx) ([]model.User, error)
+	UpdateRole(ctx context.Context, tx helpers.Tx, user model.User) (model.User, error)
+}
+
+type UserRepositoryImpl struct {
+}
+
+func NewUserRepository() UserRepository {
+	return &UserRepositoryImpl{}
+}
+
+func (repository *UserRepositoryImpl) Save(ctx context.Context, tx helpers.Tx, user model.User) (model.User, error) {
+	query := "INSERT INTO users(name, email, password, role) VALUES ($1, $2, $3, $4) RETURNING id"
+	row := tracing.QueryRowContext(ctx, tx, query, user.Name, user.Email, user.Password, user.Role)
+	err := row.Scan(&user.Id)
+
+	return user, err
+}
+
+func (repository *UserRepositoryImpl) FindByEmail(ctx context.Context, tx helpers.Tx, email string) (model.User, error) {
+	query := "SELECT id, name, email, password, role FROM users WHERE email = $1"
+	return repository.findOne(ctx, tx, query, email)
+}
+
+func (repository *UserRepositoryImpl) FindById(ctx context.Context, tx helpers.Tx, userId int) (model.User, error) {
+	query := "SELECT id, name, email, password, role FROM users WHERE id = $1"
+	return repository.findOne(ctx, tx, query, userId)
+}
+
+func (repository *UserRepositoryImpl) FindAll(ctx context.Context, tx helpers.Tx) ([]model.User, error) {
+	query := "SELECT id, name, email, password, role FROM users ORDER BY id"
+	rows, err := tracing.QueryContext(ctx, tx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		user := model.User{}
+		err := rows.Scan(&user.Id, &user.Name, &user.Email, &user.Password, &user.Role)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (repository *UserRepositoryImpl) UpdateRole(ctx context.Context, tx helpers.Tx, user model.User) (model.User, error) {
+	query := "UPDATE users SET role = $1 WHERE id = $2"
+	_, err := tracing.ExecContext(ctx, tx, query, user.Role, user.Id)
+
+	return user, err
+}
+
+func (repository *UserRepositoryImpl) findOne(ctx context.Context, tx helpers.Tx, query string, arg interface{}) (model.User, error) {
+	rows, err := tracing.QueryContext(ctx, tx, query, arg)
+	user := model.User{}
+	if err != nil {
+		return user, err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		err := rows.Scan(&user.Id, &user.Name, &user.Email, &user.Password, &user.Role)
+		return user, err
+	} else {
+		return user, exception.NewNotFoundError("user Not Found")
+	}
+}
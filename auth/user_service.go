@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"task-one/auth/dto"
+	"task-one/auth/model"
+	"task-one/auth/response"
+	"task-one/exception"
+	"task-one/helpers"
+)
+
+type UserService interface {
+	FindAll(ctx context.Context) ([]response.UserResponse, error)
+	UpdateRole(ctx context.Context, request *dto.UpdateRoleDto) (response.UserResponse, error)
+}
+
+type UserServiceImpl struct {
+	Repository UserRepository
+	TxManager  helpers.TxManager
+	Logger     *slog.Logger
+}
+
+func NewUserService(repository UserRepository, txManager helpers.TxManager, logger *slog.Logger) UserService {
+	return &UserServiceImpl{Repository: repository, TxManager: txManager, Logger: logger}
+}
+
+func (service *UserServiceImpl) FindAll(ctx context.Context) (userResponses []response.UserResponse, err error) {
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
+
+	users, err := service.Repository.FindAll(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToUserResponses(users), nil
+}
+
+func (service *UserServiceImpl) UpdateRole(ctx context.Context, request *dto.UpdateRoleDto) (userResponse response.UserResponse, err error) {
+	if !IsValidRole(request.Role) {
+		return userResponse, exception.NewBadRequestError("unknown role " + request.Role)
+	}
+
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return userResponse, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
+
+	user, err := service.Repository.FindById(ctx, tx, request.UserId)
+	if err != nil {
+		return userResponse, err
+	}
+
+	user.Role = request.Role
+	user, err = service.Repository.UpdateRole(ctx, tx, user)
+	if err != nil {
+		return userResponse, err
+	}
+
+	service.Logger.InfoContext(ctx, "user role changed", slog.Int("user_id", user.Id), slog.String("role", user.Role))
+
+	return model.ToUserResponse(user), nil
+}
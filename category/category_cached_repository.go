@@ -0,0 +1,122 @@
+package category
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"task-one/category/model"
+	"task-one/configs/cache"
+	"task-one/helpers"
+)
+
+const (
+	categoryListCachePrefix = "list:categories:"
+	categoryListCacheName   = "list:categories"
+	categoryCachePrefix     = "category:"
+	categoryCacheName       = "category"
+
+	// productListCachePrefix is the namespace of the product list pages, which
+	// carry category names and so go stale when a category changes.
+	productListCachePrefix = "list:products:"
+)
+
+// CategoryCachedRepository caches the categories of another
+// CategoryRepository under category:{id} and its list pages in a
+// cache.Namespace, reading through a cache.Loader. A write deletes the entry
+// of the category it touches and swaps the list versions, once its
+// transaction commits.
+type CategoryCachedRepository struct {
+	Repository   CategoryRepository
+	Loader       *cache.Loader
+	Lists        cache.Namespace
+	ProductLists cache.Namespace
+	ListTTL      helpers.CacheTTL
+	TTL          helpers.CacheTTL
+	Logger       *slog.Logger
+}
+
+// categoryPage is what gets cached for one list query.
+type categoryPage struct {
+	Categories []model.Category `json:"categories"`
+	PageInfo   helpers.PageInfo `json:"page_info"`
+}
+
+func NewCategoryCachedRepository(repository CategoryRepository, loader *cache.Loader, config *helpers.CacheConfig, logger *slog.Logger) CategoryRepository {
+	return &CategoryCachedRepository{
+		Repository:   repository,
+		Loader:       loader,
+		Lists:        cache.NewNamespace(loader.Cache, categoryListCachePrefix, logger),
+		ProductLists: cache.NewNamespace(loader.Cache, productListCachePrefix, logger),
+		ListTTL:      config.TTLFor(categoryListCacheName),
+		TTL:          config.TTLFor(categoryCacheName),
+		Logger:       logger,
+	}
+}
+
+func (repository *CategoryCachedRepository) Save(ctx context.Context, tx helpers.Tx, category model.Category) (model.Category, error) {
+	category, err := repository.Repository.Save(ctx, tx, category)
+	if err != nil {
+		return category, err
+	}
+	cache.AfterCommit(ctx, tx, repository.Logger, repository.Lists.Invalidate)
+	return category, nil
+}
+
+func (repository *CategoryCachedRepository) Update(ctx context.Context, tx helpers.Tx, category model.Category) (model.Category, error) {
+	category, err := repository.Repository.Update(ctx, tx, category)
+	if err != nil {
+		return category, err
+	}
+	repository.invalidate(ctx, tx, category.Id)
+	return category, nil
+}
+
+func (repository *CategoryCachedRepository) Delete(ctx context.Context, tx helpers.Tx, categoryId int) error {
+	err := repository.Repository.Delete(ctx, tx, categoryId)
+	if err != nil {
+		return err
+	}
+	repository.invalidate(ctx, tx, categoryId)
+	return nil
+}
+
+func (repository *CategoryCachedRepository) FindAll(ctx context.Context, tx helpers.Tx, query helpers.ListQuery) ([]model.Category, helpers.PageInfo, error) {
+	key, err := repository.Lists.Key(ctx, query.CacheKey())
+	if err != nil {
+		return nil, helpers.PageInfo{}, err
+	}
+
+	page, err := cache.Load(ctx, repository.Loader, tx, categoryListCacheName, key, repository.ListTTL, func(ctx context.Context, tx helpers.Tx) (page categoryPage, err error) {
+		page.Categories, page.PageInfo, err = repository.Repository.FindAll(ctx, tx, query)
+		return page, err
+	})
+	return page.Categories, page.PageInfo, err
+}
+
+func (repository *CategoryCachedRepository) FindById(ctx context.Context, tx helpers.Tx, categoryId int) (model.Category, error) {
+	return cache.Load(ctx, repository.Loader, tx, categoryCacheName, categoryCacheKey(categoryId), repository.TTL, func(ctx context.Context, tx helpers.Tx) (model.Category, error) {
+		return repository.Repository.FindById(ctx, tx, categoryId)
+	})
+}
+
+// invalidate evicts the entry of categoryId, the category pages and, since
+// renaming or deleting a category changes the rows they show, the product
+// pages, once tx commits.
+func (repository *CategoryCachedRepository) invalidate(ctx context.Context, tx helpers.Tx, categoryId int) {
+	cache.AfterCommit(ctx, tx, repository.Logger, func(ctx context.Context) error {
+		err := repository.Loader.Invalidate(ctx, categoryCacheKey(categoryId))
+		if err != nil {
+			return err
+		}
+
+		err = repository.Lists.Invalidate(ctx)
+		if err != nil {
+			return err
+		}
+		return repository.ProductLists.Invalidate(ctx)
+	})
+}
+
+func categoryCacheKey(categoryId int) string {
+	return categoryCachePrefix + strconv.Itoa(categoryId)
+}
@@ -2,9 +2,10 @@ package category
 
 import (
 	"github.com/julienschmidt/httprouter"
+	"log/slog"
 	"net/http"
-	"strconv"
 	"task-one/category/dto"
+	"task-one/exception"
 	"task-one/helpers"
 )
 
@@ -18,17 +19,27 @@ type CategoryController interface {
 
 type CategoryControllerImpl struct {
 	Service CategoryService
+	Logger  *slog.Logger
 }
 
-func NewCategoryController(categoryService CategoryService) CategoryController {
-	return &CategoryControllerImpl{Service: categoryService}
+func NewCategoryController(categoryService CategoryService, logger *slog.Logger) CategoryController {
+	return &CategoryControllerImpl{Service: categoryService, Logger: logger}
 }
 
 func (controller *CategoryControllerImpl) Create(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	categoryRequest := &dto.CategoryCreateDto{}
-	helpers.ReadFromRequestBody(request, categoryRequest)
+	err := helpers.ReadFromRequestBody(request, categoryRequest)
+	if err != nil {
+		controller.Logger.DebugContext(request.Context(), "invalid category body", slog.Any("error", err))
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	data := controller.Service.Create(request.Context(), categoryRequest)
+	data, err := controller.Service.Create(request.Context(), categoryRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 	result := helpers.ApiResponse{
 		StatusCode: 201,
 		Data:       data,
@@ -40,15 +51,26 @@ func (controller *CategoryControllerImpl) Create(writer http.ResponseWriter, req
 
 func (controller *CategoryControllerImpl) Update(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	categoryUpdateRequest := &dto.CategoryUpdateDto{}
-	helpers.ReadFromRequestBody(request, categoryUpdateRequest)
+	err := helpers.ReadFromRequestBody(request, categoryUpdateRequest)
+	if err != nil {
+		controller.Logger.DebugContext(request.Context(), "invalid category body", slog.Any("error", err))
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	categoryId := params.ByName("id")
-	res, err := strconv.Atoi(categoryId)
-	helpers.PanicIfError(err)
+	categoryId, err := helpers.ParamToInt(params, "id")
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	categoryUpdateRequest.Id = res
+	categoryUpdateRequest.Id = categoryId
 
-	data := controller.Service.Update(request.Context(), categoryUpdateRequest)
+	data, err := controller.Service.Update(request.Context(), categoryUpdateRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 	result := helpers.ApiResponse{
 		StatusCode: 201,
 		Data:       data,
@@ -57,11 +79,17 @@ func (controller *CategoryControllerImpl) Update(writer http.ResponseWriter, req
 }
 
 func (controller *CategoryControllerImpl) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	categoryId := params.ByName("id")
-	res, err := strconv.Atoi(categoryId)
-	helpers.PanicIfError(err)
+	categoryId, err := helpers.ParamToInt(params, "id")
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	controller.Service.Delete(request.Context(), res)
+	err = controller.Service.Delete(request.Context(), categoryId)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 	result := helpers.ApiResponse{
 		StatusCode: 200,
 		Data:       nil,
@@ -71,11 +99,17 @@ func (controller *CategoryControllerImpl) Delete(writer http.ResponseWriter, req
 }
 
 func (controller *CategoryControllerImpl) FindById(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	categoryId := params.ByName("id")
-	res, err := strconv.Atoi(categoryId)
-	helpers.PanicIfError(err)
+	categoryId, err := helpers.ParamToInt(params, "id")
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	data := controller.Service.FindById(request.Context(), res)
+	data, err := controller.Service.FindById(request.Context(), categoryId)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 	result := helpers.ApiResponse{
 		StatusCode: 200,
 		Data:       data,
@@ -85,10 +119,22 @@ func (controller *CategoryControllerImpl) FindById(writer http.ResponseWriter, r
 }
 
 func (controller *CategoryControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	categoryResponses := controller.Service.FindAll(request.Context())
+	query, err := helpers.ParseListQuery(request.URL.Query(), CategorySortFields, CategoryFilters)
+	if err != nil {
+		controller.Logger.DebugContext(request.Context(), "invalid category list query", slog.Any("error", err))
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+
+	categoryResponses, pageInfo, err := controller.Service.FindAll(request.Context(), query)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 	result := helpers.ApiResponse{
 		StatusCode: 200,
 		Data:       categoryResponses,
+		Meta:       helpers.NewMeta(request, query, pageInfo),
 	}
 	helpers.WriteToResponse(writer, result, 200)
 }
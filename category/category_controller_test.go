@@ -2,33 +2,78 @@ package category
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"github.com/go-playground/assert/v2"
 	"github.com/julienschmidt/httprouter"
-	_ "github.com/lib/pq"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"strings"
-	"task-one/category/model"
-	"task-one/configs/database"
+	"task-one/auth"
+	user_model "task-one/auth/model"
+	"task-one/configs/cache"
+	"task-one/configs/ratelimit"
 	"task-one/exception"
+	"task-one/helpers"
+	"task-one/testutil"
 	"testing"
+	"time"
 )
 
-func setupRouter(db *sql.DB) http.Handler {
+var env = testutil.Config()
+
+var tokens = auth.NewTokenManager(&helpers.JWTConfig{
+	Secret:          "test-secret",
+	AccessTokenTTL:  time.Minute,
+	RefreshTokenTTL: time.Hour,
+})
+
+// testBackend holds the repositories over testutil.Backend, behind the cache
+// kept in a fake Redis.
+type testBackend struct {
+	testutil.Backend
+	Repository CategoryRepository
+	ApiKeys    auth.ApiKeyRepository
+	Cache      *testutil.FakeRedis
+}
+
+func newTestBackend() testBackend {
+	backend := testBackend{Backend: testutil.NewBackend(env.DB, "product", "category"), Cache: testutil.NewFakeRedis()}
+	if backend.InMemory() {
+		backend.Repository = NewCategoryMemoryRepository()
+		backend.ApiKeys = auth.NewApiKeyMemoryRepository()
+	} else {
+		backend.Repository = NewCategoryRepository(backend.Dialect, slog.Default())
+		backend.ApiKeys = auth.NewApiKeyRepository(backend.Dialect)
+	}
+
+	loader := cache.NewLoader(cache.NewRedisCache(backend.Cache), cache.NewRedisLocker(backend.Cache), backend.TxManager, backend.Serial(), slog.Default())
+	backend.Repository = NewCategoryCachedRepository(backend.Repository, loader, env.Cache, slog.Default())
+	return backend
+}
+
+func (backend testBackend) fixtures(t *testing.T) *testutil.Fixtures {
+	return &testutil.Fixtures{T: t, TxManager: backend.TxManager, Categories: backend.Repository}
+}
+
+func setupRouter(backend testBackend) http.Handler {
 	router := httprouter.New()
 	router.PanicHandler = exception.ErrorHandler
-	RegisterRoute(router, db)
+	apiKeyService := auth.NewApiKeyService(backend.ApiKeys, backend.TxManager, slog.Default())
+	RegisterRoute(router, backend.TxManager, backend.Repository, auth.NewAuthMiddleware(tokens, apiKeyService), ratelimit.NewUnlimitedMiddleware(), ratelimit.NewUnlimitedMiddleware(), slog.Default())
 
 	return router
+}
 
+func authorize(request *http.Request) {
+	authorizeAs(request, auth.RoleEditor)
 }
 
-func truncateCategory(db *sql.DB) {
-	db.Exec("TRUNCATE category")
+func authorizeAs(request *http.Request, role string) {
+	token, _ := tokens.GenerateAccessToken(user_model.User{Id: 1, Email: "test@mail.com", Role: role})
+	request.Header.Add("Authorization", "Bearer "+token)
 }
 
 func TestMain(m *testing.M) {
@@ -36,11 +81,11 @@ func TestMain(m *testing.M) {
 }
 
 func TestGetListCategory(t *testing.T) {
-	db := database.ConnectToDbTest()
-	router := setupRouter(db)
+	router := setupRouter(newTestBackend())
 
 	req := httptest.NewRequest("GET", "http://localhost:3001/categories", nil)
 	req.Header.Add("Content-Type", "application/json")
+	authorize(req)
 
 	recorder := httptest.NewRecorder()
 
@@ -49,14 +94,94 @@ func TestGetListCategory(t *testing.T) {
 	assert.Equal(t, 200, res.StatusCode)
 }
 
+func TestGetListCategoryPaginated(t *testing.T) {
+	backend := newTestBackend()
+	router := setupRouter(backend)
+
+	fixtures := backend.fixtures(t)
+	for _, name := range []string{"Alpha", "Beta", "Gamma"} {
+		fixtures.Category().Name(name).Create()
+	}
+
+	req := httptest.NewRequest("GET", "http://localhost:3001/categories?limit=2&sort=-name", nil)
+	req.Header.Add("Content-Type", "application/json")
+	authorize(req)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+
+	data := responseBody["data"].([]interface{})
+	meta := responseBody["meta"].(map[string]interface{})
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, 2, len(data))
+	assert.Equal(t, "Gamma", data[0].(map[string]interface{})["name"])
+	assert.Equal(t, float64(3), meta["total"])
+	assert.Equal(t, "/categories?limit=2&page=2&sort=-name", meta["next"])
+
+	t.Run("Test Get List Category Invalid Sort", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "http://localhost:3001/categories?sort=password", nil)
+		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, 400, recorder.Result().StatusCode)
+	})
+}
+
+func TestCategoryCache(t *testing.T) {
+	backend := newTestBackend()
+	router := setupRouter(backend)
+	category := backend.fixtures(t).Category().Name("Furniture").Create()
+	path := "http://localhost:3001/categories/" + strconv.Itoa(category.Id)
+	productPages := cache.NewNamespace(cache.NewRedisCache(backend.Cache), "list:products:", slog.Default())
+	productPage, _ := productPages.Key(context.Background(), "page=1")
+
+	get := func(url string) interface{} {
+		req := httptest.NewRequest("GET", url, nil)
+		authorize(req)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		var responseBody map[string]interface{}
+		json.NewDecoder(recorder.Result().Body).Decode(&responseBody)
+		return responseBody["data"]
+	}
+
+	get("http://localhost:3001/categories")
+	assert.Equal(t, "Furniture", get(path).(map[string]interface{})["name"])
+	assert.Equal(t, 1, len(backend.Cache.Keys("category:")))
+
+	req := httptest.NewRequest("PATCH", path, strings.NewReader(`{"name" : "Mebel"}`))
+	req.Header.Add("Content-Type", "application/json")
+	authorize(req)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, 201, recorder.Result().StatusCode)
+	assert.Equal(t, "Mebel", get(path).(map[string]interface{})["name"])
+	assert.Equal(t, "Mebel", get("http://localhost:3001/categories").([]interface{})[0].(map[string]interface{})["name"])
+
+	// Product pages show category names, so the rename moves them to a new
+	// version too.
+	current, _ := productPages.Key(context.Background(), "page=1")
+	assert.NotEqual(t, productPage, current)
+}
+
 func TestCreateCategory(t *testing.T) {
-	db := database.ConnectToDbTest()
-	router := setupRouter(db)
-	truncateCategory(db)
+	backend := newTestBackend()
+	router := setupRouter(backend)
 
 	reqBody := strings.NewReader(`{"name" : "Website"}`)
 	req := httptest.NewRequest("POST", "http://localhost:3001/categories", reqBody)
 	req.Header.Add("Content-Type", "application/json")
+	authorize(req)
 
 	recorder := httptest.NewRecorder()
 	router.ServeHTTP(recorder, req)
@@ -72,23 +197,107 @@ func TestCreateCategory(t *testing.T) {
 
 }
 
-func TestUpdateCategory(t *testing.T) {
-	db := database.ConnectToDbTest()
-	truncateCategory(db)
-	router := setupRouter(db)
+func TestCreateCategoryValidation(t *testing.T) {
+	router := setupRouter(newTestBackend())
 
-	tx, _ := db.Begin()
+	reqBody := strings.NewReader(`{"name" : ""}`)
+	req := httptest.NewRequest("POST", "http://localhost:3001/categories", reqBody)
+	req.Header.Add("Content-Type", "application/json")
+	authorize(req)
 
-	repository := NewCategoryRepository()
-	category := repository.Save(context.Background(), tx, model.Category{
-		Name: "Handphone",
-	})
-	tx.Commit()
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+
+	body, _ := ioutil.ReadAll(res.Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+
+	assert.Equal(t, 422, res.StatusCode)
+	assert.Equal(t, "name", responseBody["errors"].([]interface{})[0].(map[string]interface{})["field"])
+}
+
+func TestCreateCategoryMalformedBody(t *testing.T) {
+	router := setupRouter(newTestBackend())
+
+	reqBody := strings.NewReader(`{"name" : `)
+	req := httptest.NewRequest("POST", "http://localhost:3001/categories", reqBody)
+	req.Header.Add("Content-Type", "application/json")
+	authorize(req)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+}
+
+func TestCreateCategoryUnauthorized(t *testing.T) {
+	router := setupRouter(newTestBackend())
+
+	reqBody := strings.NewReader(`{"name" : "Website"}`)
+	req := httptest.NewRequest("POST", "http://localhost:3001/categories", reqBody)
+	req.Header.Add("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	assert.Equal(t, 401, res.StatusCode)
+}
+
+func TestCreateCategoryForbidden(t *testing.T) {
+	router := setupRouter(newTestBackend())
+
+	reqBody := strings.NewReader(`{"name" : "Website"}`)
+	req := httptest.NewRequest("POST", "http://localhost:3001/categories", reqBody)
+	req.Header.Add("Content-Type", "application/json")
+	authorizeAs(req, auth.RoleViewer)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	assert.Equal(t, 403, res.StatusCode)
+}
+
+func TestGetListCategoryAsViewer(t *testing.T) {
+	router := setupRouter(newTestBackend())
+
+	req := httptest.NewRequest("GET", "http://localhost:3001/categories", nil)
+	req.Header.Add("Content-Type", "application/json")
+	authorizeAs(req, auth.RoleViewer)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	assert.Equal(t, 200, res.StatusCode)
+}
+
+func TestGetListCategoryAnonymous(t *testing.T) {
+	router := setupRouter(newTestBackend())
+
+	req := httptest.NewRequest("GET", "http://localhost:3001/categories", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, 200, recorder.Result().StatusCode)
+}
+
+func TestUpdateCategory(t *testing.T) {
+	backend := newTestBackend()
+	router := setupRouter(backend)
+
+	category := backend.fixtures(t).Category().Name("Handphone").Create()
 
 	t.Run("Test Update Category Success", func(t *testing.T) {
 		reqBody := strings.NewReader(`{"name" : "Not Handphone"}`)
 		req := httptest.NewRequest("PATCH", "http://localhost:3001/categories/"+strconv.Itoa(category.Id), reqBody)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
 
@@ -107,6 +316,7 @@ func TestUpdateCategory(t *testing.T) {
 		reqBody := strings.NewReader(`{"name" : "Not Handphone"}`)
 		req := httptest.NewRequest("PATCH", "http://localhost:3001/categories/"+strconv.Itoa(404), reqBody)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
 
@@ -121,21 +331,15 @@ func TestUpdateCategory(t *testing.T) {
 }
 
 func TestDeleteCategory(t *testing.T) {
-	db := database.ConnectToDbTest()
-	truncateCategory(db)
-	router := setupRouter(db)
+	backend := newTestBackend()
+	router := setupRouter(backend)
 
-	tx, _ := db.Begin()
-
-	repository := NewCategoryRepository()
-	category := repository.Save(context.Background(), tx, model.Category{
-		Name: "Delete",
-	})
-	tx.Commit()
+	category := backend.fixtures(t).Category().Name("Delete").Create()
 
 	t.Run("Test Delete Category Success", func(t *testing.T) {
 		req := httptest.NewRequest("DELETE", "http://localhost:3001/categories/"+strconv.Itoa(category.Id), nil)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
 
@@ -152,6 +356,7 @@ func TestDeleteCategory(t *testing.T) {
 	t.Run("Test Update Category Failed", func(t *testing.T) {
 		req := httptest.NewRequest("DELETE", "http://localhost:3001/categories/"+strconv.Itoa(404), nil)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
 
@@ -166,21 +371,15 @@ func TestDeleteCategory(t *testing.T) {
 }
 
 func TestGetCategoryById(t *testing.T) {
-	db := database.ConnectToDbTest()
-	truncateCategory(db)
-	router := setupRouter(db)
-
-	tx, _ := db.Begin()
+	backend := newTestBackend()
+	router := setupRouter(backend)
 
-	repository := NewCategoryRepository()
-	category := repository.Save(context.Background(), tx, model.Category{
-		Name: "Delete",
-	})
-	tx.Commit()
+	category := backend.fixtures(t).Category().Name("Delete").Create()
 
 	t.Run("Test Delete Category Success", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "http://localhost:3001/categories/"+strconv.Itoa(category.Id), nil)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
 
@@ -194,9 +393,21 @@ func TestGetCategoryById(t *testing.T) {
 
 	})
 
+	t.Run("Test Get Category With Invalid Id", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "http://localhost:3001/categories/abc", nil)
+		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		res := recorder.Result()
+		assert.Equal(t, 400, res.StatusCode)
+	})
+
 	t.Run("Test Update Category Failed", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "http://localhost:3001/categories/"+strconv.Itoa(404), nil)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
 
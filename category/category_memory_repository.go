@@ -0,0 +1,96 @@
+package category
+
+import (
+	"context"
+	"task-one/category/model"
+	"task-one/configs/memory"
+	"task-one/exception"
+	"task-one/helpers"
+)
+
+// CategoryMemoryRepository keeps categories in a map. It must be used with
+// transactions of a memory.Store, which guards the map.
+type CategoryMemoryRepository struct {
+	categories map[int]model.Category
+	nextId     int
+	referenced []func(categoryId int) bool
+}
+
+func NewCategoryMemoryRepository() *CategoryMemoryRepository {
+	return &CategoryMemoryRepository{categories: map[int]model.Category{}, nextId: 1}
+}
+
+// ReferencedBy registers a check that makes Delete refuse categories still
+// in use, standing in for the foreign key of the SQL schema.
+func (repository *CategoryMemoryRepository) ReferencedBy(check func(categoryId int) bool) {
+	repository.referenced = append(repository.referenced, check)
+}
+
+func (repository *CategoryMemoryRepository) Save(ctx context.Context, tx helpers.Tx, category model.Category) (model.Category, error) {
+	category.Id = repository.nextId
+	repository.nextId++
+	repository.put(tx, category)
+	return category, nil
+}
+
+func (repository *CategoryMemoryRepository) Update(ctx context.Context, tx helpers.Tx, category model.Category) (model.Category, error) {
+	if _, ok := repository.categories[category.Id]; ok {
+		repository.put(tx, category)
+	}
+	return category, nil
+}
+
+func (repository *CategoryMemoryRepository) Delete(ctx context.Context, tx helpers.Tx, categoryId int) error {
+	previous, ok := repository.categories[categoryId]
+	if !ok {
+		return nil
+	}
+	for _, referenced := range repository.referenced {
+		if referenced(categoryId) {
+			return exception.NewConflictError("the record is still referenced by or references another record")
+		}
+	}
+
+	delete(repository.categories, categoryId)
+	memory.OnRollback(tx, func() { repository.categories[categoryId] = previous })
+	return nil
+}
+
+func (repository *CategoryMemoryRepository) FindAll(ctx context.Context, tx helpers.Tx, query helpers.ListQuery) ([]model.Category, helpers.PageInfo, error) {
+	var categories []model.Category
+	for _, category := range repository.categories {
+		if name, ok := query.Filters["name~"]; ok && !memory.ContainsFold(category.Name, name) {
+			continue
+		}
+		categories = append(categories, category)
+	}
+
+	page, pageInfo := memory.List(categories, query, func(category model.Category, field string) interface{} {
+		if field == "name" {
+			return category.Name
+		}
+		return category.Id
+	})
+	return page, pageInfo, nil
+}
+
+func (repository *CategoryMemoryRepository) FindById(ctx context.Context, tx helpers.Tx, categoryId int) (model.Category, error) {
+	category, ok := repository.categories[categoryId]
+	if !ok {
+		return category, exception.NewNotFoundError("category Not Found")
+	}
+	return category, nil
+}
+
+// put stores category and registers how to restore what it replaced.
+func (repository *CategoryMemoryRepository) put(tx helpers.Tx, category model.Category) {
+	previous, existed := repository.categories[category.Id]
+	repository.categories[category.Id] = category
+	memory.OnRollback(tx, func() {
+		if existed {
+			repository.categories[category.Id] = previous
+		} else {
+			delete(repository.categories, category.Id)
+		}
+	})
+}
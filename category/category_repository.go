@@ -2,82 +2,164 @@ package category
 
 import (
 	"context"
-	"database/sql"
-	"errors"
+	"log/slog"
+	"strconv"
 	"task-one/category/model"
+	"task-one/configs/database"
+	"task-one/configs/tracing"
+	"task-one/exception"
 	"task-one/helpers"
 )
 
+var (
+	CategorySortFields = []string{"id", "name"}
+	CategoryFilters    = []string{"name~"}
+)
+
+var categoryColumns = map[string]string{
+	"id":   "id",
+	"name": "name",
+}
+
 type CategoryRepository interface {
-	Save(ctx context.Context, tx *sql.Tx, category model.Category) model.Category
-	Update(ctx context.Context, tx *sql.Tx, category model.Category) model.Category
-	Delete(ctx context.Context, tx *sql.Tx, categoryId int)
-	FindAll(ctx context.Context, tx *sql.Tx) []model.Category
-	FindById(ctx context.Context, tx *sql.Tx, categoryId int) (model.Category, error)
+	Save(ctx context.Context, tx helpers.Tx, category model.Category) (model.Category, error)
+	Update(ctx context.Context, tx helpers.Tx, category model.Category) (model.Category, error)
+	Delete(ctx context.Context, tx helpers.Tx, categoryId int) error
+	FindAll(ctx context.Context, tx helpers.Tx, query helpers.ListQuery) ([]model.Category, helpers.PageInfo, error)
+	FindById(ctx context.Context, tx helpers.Tx, categoryId int) (model.Category, error)
 }
 
 type CategoryRepositoryImpl struct {
+	Dialect database.Dialect
+	Logger  *slog.Logger
 }
 
-func NewCategoryRepository() CategoryRepository {
-	return &CategoryRepositoryImpl{}
+func NewCategoryRepository(dialect database.Dialect, logger *slog.Logger) CategoryRepository {
+	return &CategoryRepositoryImpl{Dialect: dialect, Logger: logger}
 }
 
-func (repository *CategoryRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, category model.Category) model.Category {
+func (repository *CategoryRepositoryImpl) Save(ctx context.Context, tx helpers.Tx, category model.Category) (model.Category, error) {
 	query := "INSERT INTO category(name) values ($1) RETURNING id"
-	row := tx.QueryRowContext(ctx, query, category.Name)
+	row := tracing.QueryRowContext(ctx, tx, query, category.Name)
 	err := row.Scan(&category.Id)
-	helpers.PanicIfError(err)
 
-	return category
+	return category, err
 }
 
-func (repository *CategoryRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, category model.Category) model.Category {
+func (repository *CategoryRepositoryImpl) Update(ctx context.Context, tx helpers.Tx, category model.Category) (model.Category, error) {
 	query := "UPDATE category set name = $1 where id = $2"
-	_, err := tx.ExecContext(ctx, query, category.Name, category.Id)
-	helpers.PanicIfError(err)
-
-	return category
+	_, err := tracing.ExecContext(ctx, tx, query, category.Name, category.Id)
 
+	return category, err
 }
 
-func (repository *CategoryRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, categoryId int) {
+func (repository *CategoryRepositoryImpl) Delete(ctx context.Context, tx helpers.Tx, categoryId int) error {
 	query := "DELETE FROM category where id = $1"
-	_, err := tx.ExecContext(ctx, query, categoryId)
-	helpers.PanicIfError(err)
+	_, err := tracing.ExecContext(ctx, tx, query, categoryId)
+	return err
+}
+
+func (repository *CategoryRepositoryImpl) FindAll(ctx context.Context, tx helpers.Tx, query helpers.ListQuery) ([]model.Category, helpers.PageInfo, error) {
+	repository.Logger.DebugContext(ctx, "listing categories", slog.String("query", query.CacheKey()))
+	if query.CursorMode {
+		return repository.findAfter(ctx, tx, query)
+	}
+
+	pageInfo := helpers.PageInfo{}
+	conditions, args := categoryConditions(repository.Dialect, query)
+	where := helpers.WhereClause(conditions)
+
+	err := tracing.QueryRowContext(ctx, tx, "SELECT COUNT(*) FROM category"+where, args...).Scan(&pageInfo.Total)
+	if err != nil {
+		return nil, pageInfo, err
+	}
+
+	SQL := "SELECT id,name FROM category" + where + query.OrderBy(categoryColumns) +
+		" LIMIT $" + strconv.Itoa(len(args)+1) + " OFFSET $" + strconv.Itoa(len(args)+2)
+	categories, err := repository.findCategories(ctx, tx, SQL, append(args, query.Limit, query.Offset())...)
+	return categories, pageInfo, err
 }
 
-func (repository *CategoryRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) []model.Category {
-	query := "SELECT id,name FROM category"
-	rows, err := tx.QueryContext(ctx, query)
-	helpers.PanicIfError(err)
+// findAfter reads one keyset page. It asks for one row more than the limit to
+// learn whether a next page exists without counting the table.
+func (repository *CategoryRepositoryImpl) findAfter(ctx context.Context, tx helpers.Tx, query helpers.ListQuery) ([]model.Category, helpers.PageInfo, error) {
+	pageInfo := helpers.PageInfo{}
+	conditions, args := categoryConditions(repository.Dialect, query)
+	if query.Cursor != nil {
+		condition, cursorArgs := query.KeysetCondition(categoryColumns, len(args))
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
+	}
+
+	SQL := "SELECT id,name FROM category" + helpers.WhereClause(conditions) + query.OrderBy(categoryColumns) +
+		" LIMIT $" + strconv.Itoa(len(args)+1)
+	categories, err := repository.findCategories(ctx, tx, SQL, append(args, query.Limit+1)...)
+	if err != nil {
+		return nil, pageInfo, err
+	}
+
+	if len(categories) > query.Limit {
+		categories = categories[:query.Limit]
+		last := categories[len(categories)-1]
+		pageInfo.NextCursor = query.NextCursor(categorySortValue(last, query.Sort[0].Field), last.Id)
+	}
+	return categories, pageInfo, nil
+}
+
+func (repository *CategoryRepositoryImpl) findCategories(ctx context.Context, tx helpers.Tx, SQL string, args ...interface{}) ([]model.Category, error) {
+	rows, err := tracing.QueryContext(ctx, tx, SQL, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 	var categories []model.Category
 
 	for rows.Next() {
 		category := model.Category{}
 		err := rows.Scan(&category.Id, &category.Name)
-		helpers.PanicIfError(err)
+		if err != nil {
+			return nil, err
+		}
 
 		categories = append(categories, category)
 
 	}
 
-	return categories
+	return categories, rows.Err()
 }
 
-func (repository *CategoryRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, categoryId int) (model.Category, error) {
+func (repository *CategoryRepositoryImpl) FindById(ctx context.Context, tx helpers.Tx, categoryId int) (model.Category, error) {
 	SQL := "SELECT id, name FROM category WHERE id = $1"
-	rows, err := tx.QueryContext(ctx, SQL, categoryId)
-	helpers.PanicIfError(err)
+	rows, err := tracing.QueryContext(ctx, tx, SQL, categoryId)
+	category := model.Category{}
+	if err != nil {
+		return category, err
+	}
 	defer rows.Close()
 
-	category := model.Category{}
 	if rows.Next() {
 		err := rows.Scan(&category.Id, &category.Name)
-		helpers.PanicIfError(err)
-		return category, nil
+		return category, err
 	} else {
-		return category, errors.New("category Not Found")
+		return category, exception.NewNotFoundError("category Not Found")
+	}
+}
+
+func categoryConditions(dialect database.Dialect, query helpers.ListQuery) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if name, ok := query.Filters["name~"]; ok {
+		args = append(args, helpers.ContainsPattern(name))
+		conditions = append(conditions, dialect.ILike("name", "$"+strconv.Itoa(len(args))))
+	}
+
+	return conditions, args
+}
+
+func categorySortValue(category model.Category, field string) string {
+	if field == "name" {
+		return category.Name
 	}
+	return strconv.Itoa(category.Id)
 }
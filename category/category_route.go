@@ -1,19 +1,21 @@
 package category
 
 import (
-	"database/sql"
 	"github.com/julienschmidt/httprouter"
+	"log/slog"
+	"task-one/auth"
+	"task-one/configs/ratelimit"
+	"task-one/helpers"
 )
 
-func RegisterRoute(router *httprouter.Router, db *sql.DB) {
+func RegisterRoute(router *httprouter.Router, txManager helpers.TxManager, categoryRepository CategoryRepository, authMiddleware auth.AuthMiddleware, limiter ratelimit.Middleware, principalLimiter ratelimit.Middleware, logger *slog.Logger) {
 
-	categoryRepository := NewCategoryRepository()
-	categoryService := NewCategoryService(categoryRepository, db)
-	categoryController := NewCategoryController(categoryService)
+	categoryService := NewCategoryService(categoryRepository, txManager, logger)
+	categoryController := NewCategoryController(categoryService, logger)
 
-	router.POST("/categories", categoryController.Create)
-	router.GET("/categories", categoryController.FindAll)
-	router.GET("/categories/:id", categoryController.FindById)
-	router.DELETE("/categories/:id", categoryController.Delete)
-	router.PATCH("/categories/:id", categoryController.Update)
+	router.POST("/categories", limiter.Limit("POST /categories", authMiddleware.Authorize(auth.CategoriesWrite, principalLimiter.Limit("POST /categories", categoryController.Create))))
+	router.GET("/categories", limiter.Limit("GET /categories", categoryController.FindAll))
+	router.GET("/categories/:id", limiter.Limit("GET /categories/:id", categoryController.FindById))
+	router.DELETE("/categories/:id", limiter.Limit("DELETE /categories/:id", authMiddleware.Authorize(auth.CategoriesWrite, principalLimiter.Limit("DELETE /categories/:id", categoryController.Delete))))
+	router.PATCH("/categories/:id", limiter.Limit("PATCH /categories/:id", authMiddleware.Authorize(auth.CategoriesWrite, principalLimiter.Limit("PATCH /categories/:id", categoryController.Update))))
 }
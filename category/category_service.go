@@ -2,98 +2,140 @@ package category
 
 import (
 	"context"
-	"database/sql"
+	"log/slog"
 	"task-one/category/dto"
 	"task-one/category/model"
 	"task-one/category/response"
-	"task-one/exception"
+	"task-one/configs/tracing"
 	"task-one/helpers"
 )
 
 type CategoryService interface {
-	Create(ctx context.Context, request *dto.CategoryCreateDto) response.CategoryResponse
-	Update(ctx context.Context, request *dto.CategoryUpdateDto) response.CategoryResponse
-	Delete(ctx context.Context, categoryId int)
-	FindById(ctx context.Context, categoryId int) response.CategoryResponse
-	FindAll(ctx context.Context) []response.CategoryResponse
+	Create(ctx context.Context, request *dto.CategoryCreateDto) (response.CategoryResponse, error)
+	Update(ctx context.Context, request *dto.CategoryUpdateDto) (response.CategoryResponse, error)
+	Delete(ctx context.Context, categoryId int) error
+	FindById(ctx context.Context, categoryId int) (response.CategoryResponse, error)
+	FindAll(ctx context.Context, query helpers.ListQuery) ([]response.CategoryResponse, helpers.PageInfo, error)
 }
 
 type CategoryServiceImpl struct {
 	Repository CategoryRepository
-	DB         *sql.DB
+	TxManager  helpers.TxManager
+	Logger     *slog.Logger
 }
 
-func NewCategoryService(repository CategoryRepository, DB *sql.DB) CategoryService {
+func NewCategoryService(repository CategoryRepository, txManager helpers.TxManager, logger *slog.Logger) CategoryService {
 	return &CategoryServiceImpl{
 		Repository: repository,
-		DB:         DB,
+		TxManager:  txManager,
+		Logger:     logger,
 	}
 }
 
-func (service *CategoryServiceImpl) Create(ctx context.Context, request *dto.CategoryCreateDto) response.CategoryResponse {
+func (service *CategoryServiceImpl) Create(ctx context.Context, request *dto.CategoryCreateDto) (categoryResponse response.CategoryResponse, err error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.Create")
+	defer func() { tracing.End(span, err) }()
 
-	tx, err := service.DB.Begin()
-	helpers.PanicIfError(err)
-
-	defer helpers.CommitOrRollback(tx)
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return categoryResponse, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
 
 	category := model.Category{
 		Name: request.Name,
 	}
 
-	category = service.Repository.Save(ctx, tx, category)
-	return helpers.ToCategoryResponse(category)
+	category, err = service.Repository.Save(ctx, tx, category)
+	if err != nil {
+		return categoryResponse, err
+	}
+
+	service.Logger.InfoContext(ctx, "category created", slog.Int("category_id", category.Id))
+	return helpers.ToCategoryResponse(category), nil
 
 }
 
-func (service *CategoryServiceImpl) Update(ctx context.Context, request *dto.CategoryUpdateDto) response.CategoryResponse {
+func (service *CategoryServiceImpl) Update(ctx context.Context, request *dto.CategoryUpdateDto) (categoryResponse response.CategoryResponse, err error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.Update")
+	defer func() { tracing.End(span, err) }()
 
-	tx, err := service.DB.Begin()
-	helpers.PanicIfError(err)
-	defer helpers.CommitOrRollback(tx)
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return categoryResponse, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
 
 	category, err := service.Repository.FindById(ctx, tx, request.Id)
 	if err != nil {
-		panic(exception.NewNotFoundError(err.Error()))
+		return categoryResponse, err
 	}
 	category.Name = request.Name
-	category = service.Repository.Update(ctx, tx, category)
+	category, err = service.Repository.Update(ctx, tx, category)
+	if err != nil {
+		return categoryResponse, err
+	}
 
-	return helpers.ToCategoryResponse(category)
+	service.Logger.InfoContext(ctx, "category updated", slog.Int("category_id", category.Id))
+	return helpers.ToCategoryResponse(category), nil
 }
 
-func (service *CategoryServiceImpl) Delete(ctx context.Context, categoryId int) {
-	tx, err := service.DB.Begin()
-	helpers.PanicIfError(err)
-	defer helpers.CommitOrRollback(tx)
+func (service *CategoryServiceImpl) Delete(ctx context.Context, categoryId int) (err error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
 
 	category, err := service.Repository.FindById(ctx, tx, categoryId)
 	if err != nil {
-		panic(exception.NewNotFoundError(err.Error()))
+		return err
+	}
+
+	err = service.Repository.Delete(ctx, tx, category.Id)
+	if err != nil {
+		return err
 	}
 
-	service.Repository.Delete(ctx, tx, category.Id)
+	service.Logger.InfoContext(ctx, "category deleted", slog.Int("category_id", category.Id))
+	return nil
 }
 
-func (service *CategoryServiceImpl) FindById(ctx context.Context, categoryId int) response.CategoryResponse {
-	tx, err := service.DB.Begin()
-	helpers.PanicIfError(err)
-	defer helpers.CommitOrRollback(tx)
+func (service *CategoryServiceImpl) FindById(ctx context.Context, categoryId int) (categoryResponse response.CategoryResponse, err error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.FindById")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return categoryResponse, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
 
 	category, err := service.Repository.FindById(ctx, tx, categoryId)
 	if err != nil {
-		panic(exception.NewNotFoundError(err.Error()))
+		return categoryResponse, err
 	}
 
-	return helpers.ToCategoryResponse(category)
+	return helpers.ToCategoryResponse(category), nil
 }
 
-func (service *CategoryServiceImpl) FindAll(ctx context.Context) []response.CategoryResponse {
-	tx, err := service.DB.Begin()
-	helpers.PanicIfError(err)
-	defer helpers.CommitOrRollback(tx)
+func (service *CategoryServiceImpl) FindAll(ctx context.Context, query helpers.ListQuery) (categoryResponses []response.CategoryResponse, pageInfo helpers.PageInfo, err error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.FindAll")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return nil, pageInfo, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
 
-	categories := service.Repository.FindAll(ctx, tx)
+	categories, pageInfo, err := service.Repository.FindAll(ctx, tx, query)
+	if err != nil {
+		return nil, pageInfo, err
+	}
 
-	return helpers.ToCategoryResponses(categories)
+	return helpers.ToCategoryResponses(categories), pageInfo, nil
 }
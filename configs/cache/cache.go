@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"task-one/configs/redis"
+	"task-one/helpers"
+	"time"
+)
+
+const (
+	Redis = "redis"
+	LRU   = "lru"
+	None  = "none"
+)
+
+// writeTimeout bounds a cache write made after a commit, which no longer
+// runs on the request's deadline.
+const writeTimeout = 5 * time.Second
+
+// ErrMiss is returned by Get for a key that is missing or expired.
+var ErrMiss = errors.New("cache miss")
+
+// Cache keeps values as JSON under string keys, each stored with its own time
+// to live. A ttl of 0 keeps the entry until it is deleted or evicted.
+type Cache interface {
+	// Get decodes the value of key into value, or returns ErrMiss.
+	Get(ctx context.Context, key string, value interface{}) error
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	DeleteByPrefix(ctx context.Context, prefix string) error
+}
+
+// New returns the cache config.Driver names. rdb is only used by the Redis
+// cache.
+func New(config *helpers.CacheConfig, rdb redis.Redis) Cache {
+	switch config.Driver {
+	case LRU:
+		return NewLRUCache(config.LRUSize)
+	case None:
+		return NewNoopCache()
+	default:
+		return NewRedisCache(rdb)
+	}
+}
+
+// AfterCommit runs write once tx commits, so the cache never holds rows that
+// were rolled back. The write outlives a canceled request, whose data is
+// committed all the same, for up to writeTimeout. A failed write is only
+// logged; an entry it leaves stale expires with its TTL.
+func AfterCommit(ctx context.Context, tx helpers.Tx, logger *slog.Logger, write func(ctx context.Context) error) {
+	tx.AfterCommit(func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), writeTimeout)
+		defer cancel()
+		err := write(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "cache write failed", slog.String("error", err.Error()))
+		}
+	})
+}
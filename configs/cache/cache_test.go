@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/go-playground/assert/v2"
+	"log/slog"
+	"task-one/helpers"
+	"task-one/testutil"
+	"testing"
+	"time"
+)
+
+type page struct {
+	Names []string `json:"names"`
+	Total int      `json:"total"`
+}
+
+func TestCaches(t *testing.T) {
+	caches := map[string]Cache{
+		"redis": NewRedisCache(testutil.NewFakeRedis()),
+		"lru":   NewLRUCache(10),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			var got page
+			assert.Equal(t, ErrMiss, cache.Get(ctx, "list:products:a", &got))
+
+			cache.Set(ctx, "list:products:a", page{Names: []string{"Table"}, Total: 1}, time.Minute)
+			cache.Set(ctx, "list:products:b", page{Total: 2}, time.Minute)
+			cache.Set(ctx, "product:1", page{Total: 3}, time.Minute)
+			assert.Equal(t, nil, cache.Get(ctx, "list:products:a", &got))
+			assert.Equal(t, page{Names: []string{"Table"}, Total: 1}, got)
+
+			cache.DeleteByPrefix(ctx, "list:products:")
+			assert.Equal(t, ErrMiss, cache.Get(ctx, "list:products:b", &got))
+			assert.Equal(t, nil, cache.Get(ctx, "product:1", &got))
+
+			cache.Delete(ctx, "product:1")
+			assert.Equal(t, ErrMiss, cache.Get(ctx, "product:1", &got))
+		})
+	}
+}
+
+func TestLRUCacheExpiresAndEvicts(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRUCache(2)
+	var value int
+
+	cache.Set(ctx, "short", 1, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	assert.Equal(t, ErrMiss, cache.Get(ctx, "short", &value))
+
+	cache.Set(ctx, "a", 1, 0)
+	cache.Set(ctx, "b", 2, 0)
+	cache.Get(ctx, "a", &value)
+	cache.Set(ctx, "c", 3, 0)
+	assert.Equal(t, ErrMiss, cache.Get(ctx, "b", &value))
+	assert.Equal(t, nil, cache.Get(ctx, "a", &value))
+}
+
+func TestNewPicksTheDriver(t *testing.T) {
+	config := &helpers.CacheConfig{Driver: LRU, LRUSize: 1}
+	_, ok := New(config, nil).(*LRUCache)
+	assert.Equal(t, true, ok)
+
+	config.Driver = None
+	assert.Equal(t, ErrMiss, New(config, nil).Get(context.Background(), "key", new(int)))
+}
+
+func TestNamespaceInvalidate(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRUCache(10)
+	namespace := NewNamespace(cache, "list:products:", slog.Default())
+
+	key, err := namespace.Key(ctx, "a")
+	assert.Equal(t, nil, err)
+	again, _ := namespace.Key(ctx, "a")
+	assert.Equal(t, key, again)
+
+	assert.Equal(t, nil, namespace.Invalidate(ctx))
+	fresh, _ := namespace.Key(ctx, "a")
+	assert.NotEqual(t, key, fresh)
+
+	// A version lost to eviction must not bring back older keys.
+	cache.Delete(ctx, "list:products:version")
+	lost, _ := namespace.Key(ctx, "a")
+	assert.NotEqual(t, fresh, lost)
+	assert.NotEqual(t, key, lost)
+}
+
+func TestNamespaceWithoutRedis(t *testing.T) {
+	rdb := testutil.NewFakeRedis()
+	rdb.Err = errors.New("connection refused")
+	namespace := NewNamespace(NewRedisCache(rdb), "list:products:", slog.Default())
+
+	key, err := namespace.Key(context.Background(), "a")
+	assert.Equal(t, nil, err)
+	again, _ := namespace.Key(context.Background(), "a")
+	assert.NotEqual(t, key, again)
+}
+
+func TestAfterCommitOutlivesTheRequest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tx := &testTx{}
+	var writeErr error
+	var bounded bool
+	AfterCommit(ctx, tx, slog.Default(), func(ctx context.Context) error {
+		writeErr = ctx.Err()
+		_, bounded = ctx.Deadline()
+		return nil
+	})
+
+	cancel()
+	tx.Commit()
+
+	assert.Equal(t, nil, writeErr)
+	assert.Equal(t, true, bounded)
+}
@@ -0,0 +1,279 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/sync/singleflight"
+	"log/slog"
+	"sync"
+	"task-one/configs/metrics"
+	"task-one/helpers"
+	"time"
+)
+
+const (
+	// lockTTL bounds how long a loader keeps the lock of a key. The lock is
+	// released once the loader's transaction ends, after the entry is written
+	// if it commits, so it only lapses when the instance dies meanwhile.
+	lockTTL = 3 * time.Second
+	// lockPoll is how often a reader waiting for another instance's loader
+	// looks for the entry.
+	lockPoll = 20 * time.Millisecond
+	// sharedLoadTimeout bounds a load shared between requests, which none of
+	// their contexts can cancel. It covers waiting out another loader's lock.
+	sharedLoadTimeout = 2 * lockTTL
+	// invalidationTTL is how long Invalidate keeps loads that began before it
+	// from writing their entry, longer than any of them takes.
+	invalidationTTL = time.Minute
+)
+
+// Loader reads through a Cache and keeps a stampede on one key from reaching
+// the database: concurrent misses on an instance share a single load, the
+// instances take turns through the key's lock, and an entry past its fresh
+// time is still served while the one reader holding the lock reloads it.
+// Without a Locker, for a cache no other instance reads, there are no turns to
+// take: a stale entry is served while a load in the background reloads it,
+// and nothing waits for another transaction to commit.
+type Loader struct {
+	Cache  Cache
+	Locker Locker
+	// TxManager runs the loads shared between requests, and the refreshes in
+	// the background, in transactions of their own, so that no request reads
+	// through another's transaction or fails with its canceled context.
+	TxManager helpers.TxManager
+	// Serial tells that TxManager runs one transaction at a time. A shared
+	// load would then wait for the transaction of the caller waiting for it,
+	// and there is no other request to share with anyway, so misses are
+	// loaded in the caller's transaction.
+	Serial bool
+	Logger *slog.Logger
+
+	group   singleflight.Group
+	mu      sync.Mutex
+	holders map[string]helpers.Tx
+}
+
+// entry is what Load stores under a key.
+type entry[T any] struct {
+	Value      T         `json:"value"`
+	FreshUntil time.Time `json:"fresh_until"`
+}
+
+func NewLoader(cache Cache, locker Locker, txManager helpers.TxManager, serial bool, logger *slog.Logger) *Loader {
+	return &Loader{Cache: cache, Locker: locker, TxManager: txManager, Serial: serial, Logger: logger, holders: map[string]helpers.Tx{}}
+}
+
+// Load returns the value cached under key, calling load to fill it in tx, or
+// in the transaction of a load shared with other requests. The entry is
+// written once that transaction commits and kept for ttl.TTL, the last part
+// of it stale. name labels the cache in the cache_requests_total metric.
+func Load[T any](ctx context.Context, loader *Loader, tx helpers.Tx, name string, key string, ttl helpers.CacheTTL, load func(ctx context.Context, tx helpers.Tx) (T, error)) (T, error) {
+	cached, err := get[T](ctx, loader, key)
+	if err == nil {
+		if time.Now().Before(cached.FreshUntil) {
+			metrics.CacheRequests.WithLabelValues(name, metrics.CacheHit).Inc()
+			return cached.Value, nil
+		}
+
+		if loader.Locker == nil {
+			metrics.CacheRequests.WithLabelValues(name, metrics.CacheStale).Inc()
+			refresh(ctx, loader, key, ttl, load)
+			return cached.Value, nil
+		}
+		unlock, ok := loader.tryLock(ctx, tx, key)
+		if !ok {
+			metrics.CacheRequests.WithLabelValues(name, metrics.CacheStale).Inc()
+			return cached.Value, nil
+		}
+		metrics.CacheRequests.WithLabelValues(name, metrics.CacheMiss).Inc()
+		return fill(ctx, loader, tx, key, ttl, load, unlock)
+	} else if !errors.Is(err, ErrMiss) {
+		// A cache that cannot be read is no reason to fail the request: the
+		// database answers instead, without locking or caching anything.
+		loader.Logger.WarnContext(ctx, "cache read failed", slog.String("key", key), slog.String("error", err.Error()))
+		metrics.CacheRequests.WithLabelValues(name, metrics.CacheMiss).Inc()
+		return load(ctx, tx)
+	}
+
+	metrics.CacheRequests.WithLabelValues(name, metrics.CacheMiss).Inc()
+	if loader.holds(tx, key) {
+		// tx is filling key already. Joining the load of another reader would
+		// have it wait for the entry tx only writes once it commits.
+		return load(ctx, tx)
+	}
+	if loader.Serial {
+		return loadMissing(ctx, loader, tx, key, ttl, load)
+	}
+
+	shared := loader.share(ctx, key, func(ctx context.Context, tx helpers.Tx) (interface{}, error) {
+		return loadMissing(ctx, loader, tx, key, ttl, load)
+	})
+	var zero T
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case result := <-shared:
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		return result.Val.(T), nil
+	}
+}
+
+// share runs load once for every caller asking for key meanwhile, in a
+// transaction of its own. A caller waiting for it does so on its own context.
+func (loader *Loader) share(ctx context.Context, key string, load func(ctx context.Context, tx helpers.Tx) (interface{}, error)) <-chan singleflight.Result {
+	return loader.group.DoChan(key, func() (value interface{}, err error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sharedLoadTimeout)
+		defer cancel()
+
+		tx, err := loader.TxManager.Begin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer helpers.CommitOrRollback(tx, &err)
+		return load(ctx, tx)
+	})
+}
+
+// refresh reloads key in the background, once for all the readers finding its
+// entry stale meanwhile.
+func refresh[T any](ctx context.Context, loader *Loader, key string, ttl helpers.CacheTTL, load func(ctx context.Context, tx helpers.Tx) (T, error)) {
+	loader.share(ctx, key, func(ctx context.Context, tx helpers.Tx) (interface{}, error) {
+		value, err := fill(ctx, loader, tx, key, ttl, load, func(ctx context.Context) {})
+		if err != nil {
+			loader.Logger.WarnContext(ctx, "cache refresh failed", slog.String("key", key), slog.String("error", err.Error()))
+		}
+		return value, err
+	})
+}
+
+// loadMissing loads key once its lock is free, unless the instance that held
+// the lock has written the entry by then.
+func loadMissing[T any](ctx context.Context, loader *Loader, tx helpers.Tx, key string, ttl helpers.CacheTTL, load func(ctx context.Context, tx helpers.Tx) (T, error)) (T, error) {
+	for {
+		unlock, ok := loader.tryLock(ctx, tx, key)
+		if ok {
+			return fill(ctx, loader, tx, key, ttl, load, unlock)
+		}
+
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-time.After(lockPoll):
+		}
+		cached, err := get[T](ctx, loader, key)
+		if err == nil {
+			return cached.Value, nil
+		}
+	}
+}
+
+// get reads the entry of key. A value that was not stored by Load, such as
+// one from before entries carried their fresh time, decodes without it and is
+// reported as a miss rather than served as an empty entry.
+func get[T any](ctx context.Context, loader *Loader, key string) (entry[T], error) {
+	var cached entry[T]
+	err := loader.Cache.Get(ctx, key, &cached)
+	if err == nil && cached.FreshUntil.IsZero() {
+		return entry[T]{}, ErrMiss
+	}
+	return cached, err
+}
+
+// fill loads key and writes its entry once tx commits, unless the key was
+// invalidated meanwhile: the value may then predate the write invalidating it.
+func fill[T any](ctx context.Context, loader *Loader, tx helpers.Tx, key string, ttl helpers.CacheTTL, load func(ctx context.Context, tx helpers.Tx) (T, error), unlock func(ctx context.Context)) (T, error) {
+	invalidation := loader.invalidation(ctx, key)
+	value, err := load(ctx, tx)
+	if err != nil {
+		unlock(ctx)
+		return value, err
+	}
+
+	AfterCommit(ctx, tx, loader.Logger, func(ctx context.Context) error {
+		defer unlock(ctx)
+		if loader.invalidation(ctx, key) != invalidation {
+			return nil
+		}
+		return loader.Cache.Set(ctx, key, entry[T]{Value: value, FreshUntil: time.Now().Add(ttl.Fresh)}, ttl.TTL)
+	})
+	// A rollback often follows a canceled request, whose ctx cannot reach
+	// Redis anymore.
+	tx.AfterRollback(func() { unlock(context.WithoutCancel(ctx)) })
+	return value, nil
+}
+
+// Invalidate deletes the entry of key, and keeps the loads of key that began
+// before from writing what they read then.
+func (loader *Loader) Invalidate(ctx context.Context, key string) error {
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+	err = loader.Cache.Set(ctx, "invalidated:"+key, token, invalidationTTL)
+	if err != nil {
+		return err
+	}
+	return loader.Cache.Delete(ctx, key)
+}
+
+// invalidation returns the token of the last Invalidate of key, if it is
+// recent. A token that cannot be read counts as another one, which keeps the
+// entry from being written.
+func (loader *Loader) invalidation(ctx context.Context, key string) string {
+	var token string
+	err := loader.Cache.Get(ctx, "invalidated:"+key, &token)
+	if errors.Is(err, ErrMiss) {
+		return ""
+	}
+	if err != nil {
+		token, _ = newToken()
+	}
+	return token
+}
+
+// tryLock takes the lock of key for tx. A transaction reading a key twice
+// already holds its lock, which is only released once the transaction ends,
+// so it is let through instead of waiting for itself. Without a Locker, or
+// when the lock cannot be reached, the load goes ahead unguarded.
+func (loader *Loader) tryLock(ctx context.Context, tx helpers.Tx, key string) (func(ctx context.Context), bool) {
+	if loader.Locker == nil || loader.holds(tx, key) {
+		return func(ctx context.Context) {}, true
+	}
+
+	lockKey := "lock:" + key
+	unlock, ok, err := loader.Locker.TryLock(ctx, lockKey, lockTTL)
+	if err != nil {
+		loader.Logger.WarnContext(ctx, "cache lock unavailable", slog.String("key", key), slog.String("error", err.Error()))
+		return func(ctx context.Context) {}, true
+	}
+	if !ok {
+		return nil, false
+	}
+
+	loader.mu.Lock()
+	loader.holders[lockKey] = tx
+	loader.mu.Unlock()
+	return func(ctx context.Context) {
+		loader.mu.Lock()
+		if loader.holders[lockKey] == tx {
+			delete(loader.holders, lockKey)
+		}
+		loader.mu.Unlock()
+
+		err := unlock(ctx)
+		if err != nil {
+			loader.Logger.WarnContext(ctx, "cache unlock failed", slog.String("key", key), slog.String("error", err.Error()))
+		}
+	}, true
+}
+
+// holds tells whether tx holds the lock of key.
+func (loader *Loader) holds(tx helpers.Tx, key string) bool {
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	holder, held := loader.holders["lock:"+key]
+	return held && holder == tx
+}
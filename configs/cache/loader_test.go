@@ -0,0 +1,289 @@
+package cache
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"task-one/helpers"
+	"task-one/testutil"
+	"testing"
+	"time"
+)
+
+// testTx only runs its hooks.
+type testTx struct {
+	helpers.CommitHooks
+}
+
+func (tx *testTx) Commit() error {
+	tx.Run()
+	return nil
+}
+
+func (tx *testTx) Rollback() error {
+	tx.RunRollback()
+	return nil
+}
+
+// testTxManager begins testTxs.
+type testTxManager struct{}
+
+func (testTxManager) Begin(ctx context.Context) (helpers.Tx, error) {
+	return &testTx{}, nil
+}
+
+var testTTL = helpers.CacheTTL{Fresh: time.Minute, TTL: time.Hour}
+
+func newTestLoader(rdb *testutil.FakeRedis) *Loader {
+	return NewLoader(NewRedisCache(rdb), NewRedisLocker(rdb), testTxManager{}, true, slog.Default())
+}
+
+func TestLoadCoalescesConcurrentMisses(t *testing.T) {
+	loader := newTestLoader(testutil.NewFakeRedis())
+	var loads atomic.Int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tx := &testTx{}
+			value, err := Load(context.Background(), loader, tx, "test", "list:products:a", testTTL, func(ctx context.Context, tx helpers.Tx) (string, error) {
+				loads.Add(1)
+				time.Sleep(20 * time.Millisecond)
+				return "loaded", nil
+			})
+			tx.Commit()
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "loaded", value)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+}
+
+func TestSharedLoadOutlivesTheLeader(t *testing.T) {
+	rdb := testutil.NewFakeRedis()
+	loader := NewLoader(NewRedisCache(rdb), NewRedisLocker(rdb), testTxManager{}, false, slog.Default())
+	var loads atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func(ctx context.Context, tx helpers.Tx) (string, error) {
+		if loads.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return "loaded", ctx.Err()
+	}
+
+	leader, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := Load(leader, loader, &testTx{}, "test", "product:1", testTTL, load)
+		canceled <- err
+	}()
+	<-started
+	joined := make(chan string)
+	go func() {
+		value, err := Load(context.Background(), loader, &testTx{}, "test", "product:1", testTTL, load)
+		assert.Equal(t, nil, err)
+		joined <- value
+	}()
+	time.Sleep(2 * lockPoll)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-canceled)
+	close(release)
+	assert.Equal(t, "loaded", <-joined)
+	assert.Equal(t, int32(1), loads.Load())
+	assert.Equal(t, time.Hour, rdb.TTL("product:1"))
+}
+
+func TestLoadServesStaleWhileOneRefreshes(t *testing.T) {
+	ctx := context.Background()
+	rdb := testutil.NewFakeRedis()
+	loader := newTestLoader(rdb)
+	loader.Cache.Set(ctx, "product:1", entry[string]{Value: "old", FreshUntil: time.Now().Add(-time.Second)}, 0)
+	load := func(ctx context.Context, tx helpers.Tx) (string, error) { return "new", nil }
+
+	unlock, ok, _ := loader.Locker.TryLock(ctx, "lock:product:1", time.Minute)
+	assert.Equal(t, true, ok)
+	value, _ := Load(ctx, loader, &testTx{}, "test", "product:1", testTTL, load)
+	assert.Equal(t, "old", value)
+
+	unlock(ctx)
+	tx := &testTx{}
+	value, _ = Load(ctx, loader, tx, "test", "product:1", testTTL, load)
+	assert.Equal(t, "new", value)
+	tx.Commit()
+
+	assert.Equal(t, time.Hour, rdb.TTL("product:1"))
+	assert.Equal(t, 0, len(rdb.Keys("lock:")))
+	value, _ = Load(ctx, loader, &testTx{}, "test", "product:1", testTTL, func(ctx context.Context, tx helpers.Tx) (string, error) { return "", nil })
+	assert.Equal(t, "new", value)
+}
+
+func TestLoadReplacesForeignEntries(t *testing.T) {
+	ctx := context.Background()
+	rdb := testutil.NewFakeRedis()
+	loader := newTestLoader(rdb)
+	rdb.Set(ctx, "product:1", map[string]string{"name": "Table"}, 0)
+
+	tx := &testTx{}
+	value, err := Load(ctx, loader, tx, "test", "product:1", testTTL, func(ctx context.Context, tx helpers.Tx) (map[string]string, error) {
+		return map[string]string{"name": "Kursi"}, nil
+	})
+	tx.Commit()
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Kursi", value["name"])
+	assert.Equal(t, time.Hour, rdb.TTL("product:1"))
+}
+
+func TestLoadWaitsForAnotherInstance(t *testing.T) {
+	ctx := context.Background()
+	rdb := testutil.NewFakeRedis()
+	other := newTestLoader(rdb)
+	unlock, _, _ := other.Locker.TryLock(ctx, "lock:product:1", time.Minute)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		other.Cache.Set(ctx, "product:1", entry[string]{Value: "theirs", FreshUntil: time.Now().Add(time.Minute)}, time.Hour)
+		unlock(ctx)
+	}()
+
+	value, err := Load(ctx, newTestLoader(rdb), &testTx{}, "test", "product:1", testTTL, func(ctx context.Context, tx helpers.Tx) (string, error) {
+		return "ours", nil
+	})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "theirs", value)
+}
+
+func TestLoadTwiceInOneTx(t *testing.T) {
+	ctx := context.Background()
+	loader := newTestLoader(testutil.NewFakeRedis())
+	tx := &testTx{}
+	load := func(ctx context.Context, tx helpers.Tx) (string, error) { return "loaded", nil }
+
+	Load(ctx, loader, tx, "test", "category:1", testTTL, load)
+	start := time.Now()
+	value, _ := Load(ctx, loader, tx, "test", "category:1", testTTL, load)
+
+	assert.Equal(t, "loaded", value)
+	assert.Equal(t, true, time.Since(start) < lockTTL)
+}
+
+func TestLoadReleasesTheLockOnRollback(t *testing.T) {
+	ctx := context.Background()
+	rdb := testutil.NewFakeRedis()
+	loader := newTestLoader(rdb)
+
+	tx := &testTx{}
+	Load(ctx, loader, tx, "test", "product:1", testTTL, func(ctx context.Context, tx helpers.Tx) (string, error) { return "rolled back", nil })
+	tx.Rollback()
+
+	assert.Equal(t, 0, len(rdb.Keys("lock:")))
+	start := time.Now()
+	value, _ := Load(ctx, loader, &testTx{}, "test", "product:1", testTTL, func(ctx context.Context, tx helpers.Tx) (string, error) { return "loaded", nil })
+	assert.Equal(t, "loaded", value)
+	assert.Equal(t, true, time.Since(start) < lockTTL)
+}
+
+func TestLoadTwiceInOneTxWhileAnotherWaits(t *testing.T) {
+	ctx := context.Background()
+	loader := newTestLoader(testutil.NewFakeRedis())
+	tx := &testTx{}
+	load := func(ctx context.Context, tx helpers.Tx) (string, error) { return "loaded", nil }
+	Load(ctx, loader, tx, "test", "category:1", testTTL, load)
+
+	waiting := make(chan string)
+	go func() {
+		value, _ := Load(ctx, loader, &testTx{}, "test", "category:1", testTTL, load)
+		waiting <- value
+	}()
+	time.Sleep(2 * lockPoll)
+
+	start := time.Now()
+	value, _ := Load(ctx, loader, tx, "test", "category:1", testTTL, load)
+	assert.Equal(t, "loaded", value)
+	assert.Equal(t, true, time.Since(start) < lockTTL)
+
+	tx.Commit()
+	assert.Equal(t, "loaded", <-waiting)
+}
+
+func TestInvalidateDropsOverlappingLoads(t *testing.T) {
+	ctx := context.Background()
+	rdb := testutil.NewFakeRedis()
+	loader := newTestLoader(rdb)
+
+	tx := &testTx{}
+	Load(ctx, loader, tx, "test", "product:1", testTTL, func(ctx context.Context, tx helpers.Tx) (string, error) { return "old", nil })
+	assert.Equal(t, nil, loader.Invalidate(ctx, "product:1"))
+	tx.Commit()
+	assert.Equal(t, 0, len(rdb.Keys("product:")))
+
+	tx = &testTx{}
+	value, _ := Load(ctx, loader, tx, "test", "product:1", testTTL, func(ctx context.Context, tx helpers.Tx) (string, error) { return "new", nil })
+	tx.Commit()
+	assert.Equal(t, "new", value)
+	assert.Equal(t, 1, len(rdb.Keys("product:")))
+}
+
+func TestLoadWithoutLocker(t *testing.T) {
+	ctx := context.Background()
+	loader := NewLoader(NewLRUCache(10), nil, testTxManager{}, true, slog.Default())
+	load := func(ctx context.Context, tx helpers.Tx) (string, error) { return "loaded", nil }
+
+	// The entry of an open transaction does not hold up other readers.
+	open := &testTx{}
+	Load(ctx, loader, open, "test", "category:1", testTTL, load)
+	start := time.Now()
+	value, _ := Load(ctx, loader, &testTx{}, "test", "category:1", testTTL, load)
+	assert.Equal(t, "loaded", value)
+	assert.Equal(t, true, time.Since(start) < lockPoll)
+
+	// A stale entry is served while a single load refreshes it.
+	loader.Cache.Set(ctx, "category:1", entry[string]{Value: "old", FreshUntil: time.Now().Add(-time.Second)}, 0)
+	var loads atomic.Int32
+	release := make(chan struct{})
+	refresh := func(ctx context.Context, tx helpers.Tx) (string, error) {
+		loads.Add(1)
+		<-release
+		return "new", nil
+	}
+	for i := 0; i < 3; i++ {
+		value, _ = Load(ctx, loader, &testTx{}, "test", "category:1", testTTL, refresh)
+		assert.Equal(t, "old", value)
+	}
+	close(release)
+	for start := time.Now(); value != "new" && time.Since(start) < time.Second; time.Sleep(lockPoll) {
+		value, _ = Load(ctx, loader, &testTx{}, "test", "category:1", testTTL, load)
+	}
+	assert.Equal(t, "new", value)
+	assert.Equal(t, int32(1), loads.Load())
+}
+
+func TestRedisLocker(t *testing.T) {
+	ctx := context.Background()
+	locker := NewRedisLocker(testutil.NewFakeRedis())
+	unlock, ok, err := locker.TryLock(ctx, "lock:a", time.Minute)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+
+	_, ok, _ = locker.TryLock(ctx, "lock:a", time.Minute)
+	assert.Equal(t, false, ok)
+
+	unlock(ctx)
+	_, ok, _ = locker.TryLock(ctx, "lock:a", time.Minute)
+	assert.Equal(t, true, ok)
+
+	// A holder whose lock was taken over cannot release the new one.
+	unlock(ctx)
+	_, ok, _ = locker.TryLock(ctx, "lock:a", time.Minute)
+	assert.Equal(t, false, ok)
+}
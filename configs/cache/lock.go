@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"task-one/configs/redis"
+	"task-one/helpers"
+	"time"
+)
+
+// Locker hands the lock on a key to one holder at a time. A lock lapses after
+// its ttl, so a holder that never unlocks cannot block the key for good.
+type Locker interface {
+	// TryLock takes the lock on key without waiting. ok is false while
+	// someone else holds it.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (unlock func(ctx context.Context) error, ok bool, err error)
+}
+
+// NewLocker returns the locker matching the cache config.Driver. Entries in
+// Redis are shared by every instance, so their lock must be too; the other
+// caches are per instance, where the Loader needs no lock, and get none.
+func NewLocker(config *helpers.CacheConfig, rdb redis.Redis) Locker {
+	if config.Driver == Redis {
+		return NewRedisLocker(rdb)
+	}
+	return nil
+}
+
+// RedisLocker takes locks with SET NX PX under a random token, and only
+// deletes a lock still holding its own token when unlocking.
+type RedisLocker struct {
+	Redis redis.Redis
+}
+
+func NewRedisLocker(rdb redis.Redis) Locker {
+	return &RedisLocker{Redis: rdb}
+}
+
+func (locker *RedisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (func(ctx context.Context) error, bool, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, false, err
+	}
+
+	ok, err := locker.Redis.SetNX(ctx, key, token, ttl)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return func(ctx context.Context) error {
+		return locker.Redis.CompareAndDelete(ctx, key, token)
+	}, true, nil
+}
+
+func newToken() (string, error) {
+	random := make([]byte, 8)
+	_, err := rand.Read(random)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random), nil
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"strings"
+	"time"
+)
+
+// LRUCache keeps up to a fixed number of entries in process, dropping the
+// least recently used first. Each instance has its own entries, so a write on
+// one instance does not evict what another has cached.
+type LRUCache struct {
+	entries *lru.Cache[string, lruEntry]
+}
+
+// lruEntry holds the JSON rather than the value itself, so callers never share
+// what they decode.
+type lruEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+func NewLRUCache(size int) Cache {
+	entries, err := lru.New[string, lruEntry](size)
+	if err != nil {
+		panic(err)
+	}
+	return &LRUCache{entries: entries}
+}
+
+func (cache *LRUCache) Get(ctx context.Context, key string, value interface{}) error {
+	entry, ok := cache.entries.Get(key)
+	if !ok {
+		return ErrMiss
+	}
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		cache.entries.Remove(key)
+		return ErrMiss
+	}
+	return json.Unmarshal(entry.data, value)
+}
+
+func (cache *LRUCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	entry := lruEntry{data: data}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	cache.entries.Add(key, entry)
+	return nil
+}
+
+func (cache *LRUCache) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		cache.entries.Remove(key)
+	}
+	return nil
+}
+
+func (cache *LRUCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	for _, key := range cache.entries.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			cache.entries.Remove(key)
+		}
+	}
+	return nil
+}
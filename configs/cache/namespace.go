@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// Namespace groups keys that are always invalidated together, such as the
+// pages of a list. Its keys embed a version stored under Name + "version", so
+// Invalidate is a single write however many keys there are: it swaps the
+// version and the old keys are never read again, expiring with their TTL.
+type Namespace struct {
+	Cache  Cache
+	Name   string
+	Logger *slog.Logger
+}
+
+func NewNamespace(cache Cache, name string, logger *slog.Logger) Namespace {
+	return Namespace{Cache: cache, Name: name, Logger: logger}
+}
+
+// Key returns the key under which key is currently stored. When the version
+// cannot be read or stored, the error is logged and a key that was never
+// stored is returned, so the read goes to the database.
+func (namespace Namespace) Key(ctx context.Context, key string) (string, error) {
+	var version string
+	err := namespace.Cache.Get(ctx, namespace.versionKey(), &version)
+	if errors.Is(err, ErrMiss) {
+		// Keys of a lost version may still be cached, so a missing version
+		// starts a new one instead of falling back to a default.
+		version, err = namespace.newVersion(ctx)
+	}
+	if err != nil {
+		namespace.Logger.WarnContext(ctx, "cache version unavailable", slog.String("namespace", namespace.Name), slog.String("error", err.Error()))
+		version, err = newToken()
+		if err != nil {
+			return "", err
+		}
+	}
+	return namespace.Name + version + ":" + key, nil
+}
+
+// Invalidate orphans every key handed out by Key so far.
+func (namespace Namespace) Invalidate(ctx context.Context) error {
+	_, err := namespace.newVersion(ctx)
+	return err
+}
+
+func (namespace Namespace) newVersion(ctx context.Context) (string, error) {
+	version, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	return version, namespace.Cache.Set(ctx, namespace.versionKey(), version, 0)
+}
+
+func (namespace Namespace) versionKey() string {
+	return namespace.Name + "version"
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// NoopCache stores nothing, so every read goes to the repository.
+type NoopCache struct{}
+
+func NewNoopCache() Cache {
+	return NoopCache{}
+}
+
+func (NoopCache) Get(ctx context.Context, key string, value interface{}) error {
+	return ErrMiss
+}
+
+func (NoopCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return nil
+}
+
+func (NoopCache) Delete(ctx context.Context, keys ...string) error {
+	return nil
+}
+
+func (NoopCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	return nil
+}
@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"task-one/configs/redis"
+	"time"
+)
+
+// RedisCache shares its entries between every instance of the service.
+type RedisCache struct {
+	Redis redis.Redis
+}
+
+func NewRedisCache(rdb redis.Redis) Cache {
+	return &RedisCache{Redis: rdb}
+}
+
+func (cache *RedisCache) Get(ctx context.Context, key string, value interface{}) error {
+	data, err := cache.Redis.Get(ctx, key)
+	if errors.Is(err, redis.Nil) {
+		return ErrMiss
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), value)
+}
+
+func (cache *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return cache.Redis.Set(ctx, key, value, ttl)
+}
+
+func (cache *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	return cache.Redis.Delete(ctx, keys...)
+}
+
+func (cache *RedisCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	return cache.Redis.DeleteByPrefix(ctx, prefix)
+}
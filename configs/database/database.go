@@ -2,37 +2,46 @@ package database
 
 import (
 	"database/sql"
-	"log"
+	"log/slog"
 	"task-one/helpers"
-	"time"
 )
 
-func ConnectToDb() *sql.DB {
-	env := helpers.GetConfig()
+func ConnectToDb(config *helpers.DBConfig) (*sql.DB, Dialect) {
+	db, dialect := OpenDb(config)
 
-	db, err := sql.Open(env.DB.Connection, env.DB.URI)
-	helpers.PanicIfError(err)
+	if config.AutoMigrate {
+		err := Migrate(db, dialect)
+		helpers.PanicIfError(err)
+	}
 
-	db.SetMaxIdleConns(5)
-	db.SetMaxOpenConns(20)
-	db.SetConnMaxLifetime(60 * time.Minute)
+	return db, dialect
 
-	log.Println("Database Connected..")
+}
 
-	return db
+// OpenDb connects like ConnectToDb but never migrates, for the migrate
+// command, which has to see the schema as it is.
+func OpenDb(config *helpers.DBConfig) (*sql.DB, Dialect) {
+	dialect, err := DialectFor(config.Connection)
+	helpers.PanicIfError(err)
 
-}
+	db, err := dialect.Open(config.URI)
+	helpers.PanicIfError(err)
 
-func ConnectToDbTest() *sql.DB {
+	slog.Info("database connected", slog.String("driver", dialect.Name()))
+	return db, dialect
+}
 
-	env := helpers.GetConfig()
+func ConnectToDbTest(config *helpers.DBConfig) (*sql.DB, Dialect) {
+	dialect, err := DialectFor(config.Connection)
+	helpers.PanicIfError(err)
 
-	db, err := sql.Open(env.DB.Connection, env.DB.Test_URI)
+	db, err := dialect.Open(config.Test_URI)
 	helpers.PanicIfError(err)
 
-	db.SetMaxIdleConns(5)
-	db.SetMaxOpenConns(20)
-	db.SetConnMaxLifetime(60 * time.Minute)
+	// Tests run against a throwaway database, which is brought to the latest
+	// schema on first use.
+	err = Migrate(db, dialect)
+	helpers.PanicIfError(err)
 
-	return db
+	return db, dialect
 }
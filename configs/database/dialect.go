@@ -0,0 +1,216 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/lib/pq"
+	_ "modernc.org/sqlite"
+	"strings"
+	"time"
+)
+
+// Dialect is what differs between the SQL backends. Repositories write their
+// statements once, with Postgres style $n placeholders, and ask the dialect for
+// the few fragments that cannot be shared.
+type Dialect interface {
+	// Name is the DB_DRIVER value selecting the dialect.
+	Name() string
+	// System is the OpenTelemetry db.system of the backend.
+	System() string
+	// Open opens a connection pool to uri, tuned for the backend.
+	Open(uri string) (*sql.DB, error)
+	// Rebind rewrites the $n placeholders of query into the backend's own.
+	Rebind(query string) string
+	// ILike matches column case-insensitively against the LIKE pattern bound
+	// to placeholder, which escapes wildcards with a backslash.
+	ILike(column string, placeholder string) string
+	// Array stores a string slice in one column, for both writing and Scan.
+	Array(values *[]string) ArrayValue
+	// FullTextSearch tells whether product.search_vector exists, which only
+	// the Postgres migrations create.
+	FullTextSearch() bool
+	// Truncate empties tables, which are listed referencing tables first.
+	Truncate(tables ...string) string
+	// LockMigrations keeps other processes from migrating db until unlock is
+	// called.
+	LockMigrations(ctx context.Context, db *sql.DB) (unlock func(), err error)
+}
+
+type ArrayValue interface {
+	driver.Valuer
+	sql.Scanner
+}
+
+var (
+	Postgres Dialect = postgresDialect{}
+	SQLite   Dialect = sqliteDialect{}
+)
+
+// DialectFor returns the dialect of a DB_DRIVER value.
+func DialectFor(name string) (Dialect, error) {
+	for _, dialect := range []Dialect{Postgres, SQLite} {
+		if dialect.Name() == name {
+			return dialect, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown database driver %q", name)
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string   { return "postgres" }
+func (postgresDialect) System() string { return "postgresql" }
+
+func (postgresDialect) Open(uri string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", uri)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxIdleConns(5)
+	db.SetMaxOpenConns(20)
+	db.SetConnMaxLifetime(60 * time.Minute)
+	return db, nil
+}
+
+func (postgresDialect) Rebind(query string) string { return query }
+
+func (postgresDialect) ILike(column string, placeholder string) string {
+	return column + " ILIKE " + placeholder
+}
+
+func (postgresDialect) Array(values *[]string) ArrayValue {
+	return (*pq.StringArray)(values)
+}
+
+func (postgresDialect) FullTextSearch() bool { return true }
+
+func (postgresDialect) Truncate(tables ...string) string {
+	return "TRUNCATE " + strings.Join(tables, ", ") + " CASCADE"
+}
+
+// migrationLockKey identifies the advisory lock taken while migrating.
+const migrationLockKey = 4_618_263_051
+
+// LockMigrations takes a session-level advisory lock on a connection of its
+// own, so the migrations themselves can run on any other.
+func (postgresDialect) LockMigrations(ctx context.Context, db *sql.DB) (func(), error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return func() {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+		conn.Close()
+	}, nil
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string   { return "sqlite" }
+func (sqliteDialect) System() string { return "sqlite" }
+
+// Open uses the pure Go driver, so builds need no C toolchain. It turns on
+// foreign keys, which SQLite leaves off by default, and waits
+// for locks instead of failing at once. SQLite allows a single writer, so the
+// pool holds one connection, which also keeps a ":memory:" database alive
+// for as long as the pool.
+func (sqliteDialect) Open(uri string) (*sql.DB, error) {
+	separator := "?"
+	if strings.Contains(uri, "?") {
+		separator = "&"
+	}
+	db, err := sql.Open("sqlite", uri+separator+"_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// Rebind turns $n into ?n, which SQLite binds to the nth argument too. Quoted
+// strings and identifiers are copied unchanged.
+func (sqliteDialect) Rebind(query string) string {
+	var builder strings.Builder
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			c = '?'
+		}
+		builder.WriteByte(c)
+	}
+	return builder.String()
+}
+
+// ILike relies on LIKE, which SQLite already compares case-insensitively for
+// ASCII letters, but needs the escape character spelled out.
+func (sqliteDialect) ILike(column string, placeholder string) string {
+	return column + " LIKE " + placeholder + ` ESCAPE '\'`
+}
+
+func (sqliteDialect) Array(values *[]string) ArrayValue {
+	return (*jsonArray)(values)
+}
+
+func (sqliteDialect) FullTextSearch() bool { return false }
+
+func (sqliteDialect) Truncate(tables ...string) string {
+	var statements []string
+	for _, table := range tables {
+		statements = append(statements, "DELETE FROM "+table)
+	}
+	return strings.Join(statements, "; ")
+}
+
+// LockMigrations has nothing to take: the pool holds a single connection, so
+// a process never migrates twice at once, and a database file is meant to be
+// served by one instance.
+func (sqliteDialect) LockMigrations(ctx context.Context, db *sql.DB) (func(), error) {
+	return func() {}, nil
+}
+
+// jsonArray keeps a string slice in a TEXT column as a JSON array.
+type jsonArray []string
+
+func (array *jsonArray) Value() (driver.Value, error) {
+	if *array == nil {
+		return "[]", nil
+	}
+	content, err := json.Marshal([]string(*array))
+	return string(content), err
+}
+
+func (array *jsonArray) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(src), (*[]string)(array))
+	case []byte:
+		return json.Unmarshal(src, (*[]string)(array))
+	case nil:
+		*array = nil
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into a string array", src)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"github.com/go-playground/assert/v2"
+	"testing"
+)
+
+func TestSQLiteRebind(t *testing.T) {
+	query := `SELECT '$1', "a$2" FROM t WHERE (name, id) > ($1, $12) AND price = '$'`
+
+	assert.Equal(t, `SELECT '$1', "a$2" FROM t WHERE (name, id) > (?1, ?12) AND price = '$'`, SQLite.Rebind(query))
+	assert.Equal(t, query, Postgres.Rebind(query))
+}
+
+func TestSQLiteArrayRoundTrip(t *testing.T) {
+	scopes := []string{"products:read", "categories:write"}
+	value, err := SQLite.Array(&scopes).Value()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `["products:read","categories:write"]`, value)
+
+	var scanned []string
+	err = SQLite.Array(&scanned).Scan(value)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, scopes, scanned)
+}
+
+func TestDialectFor(t *testing.T) {
+	dialect, err := DialectFor("sqlite")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, SQLite, dialect)
+
+	_, err = DialectFor("mysql")
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"task-one/helpers"
+)
+
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+// Migration is one numbered schema change, read from the files
+// migrations/<dialect>/<version>_<name>.up.sql and .down.sql. Every dialect
+// has its own directory, numbered alike.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Migrations returns every embedded migration of dialect, ordered by version.
+func Migrations(dialect Dialect) ([]Migration, error) {
+	names, err := fs.Glob(migrationFiles, "migrations/"+dialect.Name()+"/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, name := range names {
+		base := path.Base(name)
+		direction := path.Ext(strings.TrimSuffix(base, ".sql"))
+		parts := strings.SplitN(strings.TrimSuffix(base, direction+".sql"), "_", 2)
+		version, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) != 2 || (direction != ".up" && direction != ".down") {
+			return nil, fmt.Errorf("migration file %s is not named <version>_<name>.(up|down).sql", base)
+		}
+
+		content, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = migration
+		}
+		if direction == ".up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	var migrations []Migration
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d %s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrate applies every migration that is not yet recorded in
+// schema_migrations, in order. Each one runs in its own transaction together
+// with its bookkeeping row, so a failed migration leaves no trace. Processes
+// starting together take turns through the dialect's migration lock.
+func Migrate(db *sql.DB, dialect Dialect) error {
+	unlock, err := dialect.LockMigrations(context.Background(), db)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	statuses, err := Status(db, dialect)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		if status.Applied {
+			continue
+		}
+
+		err := runMigration(db, status.Up,
+			dialect.Rebind("INSERT INTO schema_migrations(version, name) VALUES($1, $2)"), status.Version, status.Name)
+		if err != nil {
+			return fmt.Errorf("migration %04d_%s: %w", status.Version, status.Name, err)
+		}
+		slog.Info("migration applied", slog.Int("version", status.Version), slog.String("name", status.Name))
+	}
+	return nil
+}
+
+// MigrateDown reverts the latest steps applied migrations, newest first.
+func MigrateDown(db *sql.DB, dialect Dialect, steps int) error {
+	unlock, err := dialect.LockMigrations(context.Background(), db)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	statuses, err := Status(db, dialect)
+	if err != nil {
+		return err
+	}
+
+	for i := len(statuses) - 1; i >= 0 && steps > 0; i-- {
+		status := statuses[i]
+		if !status.Applied {
+			continue
+		}
+
+		err := runMigration(db, status.Down,
+			dialect.Rebind("DELETE FROM schema_migrations WHERE version = $1"), status.Version)
+		if err != nil {
+			return fmt.Errorf("revert %04d_%s: %w", status.Version, status.Name, err)
+		}
+		slog.Info("migration reverted", slog.Int("version", status.Version), slog.String("name", status.Name))
+		steps--
+	}
+	return nil
+}
+
+// Status lists every embedded migration and whether it has been applied,
+// creating the schema_migrations table if needed.
+func Status(db *sql.DB, dialect Dialect) ([]MigrationStatus, error) {
+	migrations, err := Migrations(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations
+		(
+			version    INT PRIMARY KEY,
+			name       VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]bool{}
+	for rows.Next() {
+		var version int
+		err := rows.Scan(&version)
+		if err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range migrations {
+		statuses = append(statuses, MigrationStatus{Migration: migration, Applied: applied[migration.Version]})
+	}
+	return statuses, nil
+}
+
+func runMigration(db *sql.DB, script string, bookkeeping string, args ...interface{}) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer helpers.CommitOrRollback(&Tx{Tx: tx}, &err)
+
+	_, err = tx.Exec(script)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(bookkeeping, args...)
+	return err
+}
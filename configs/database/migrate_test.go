@@ -0,0 +1,69 @@
+package database
+
+import (
+	"github.com/go-playground/assert/v2"
+	"task-one/helpers"
+	"testing"
+)
+
+func TestMigrationsAreEmbeddedInOrder(t *testing.T) {
+	for _, dialect := range []Dialect{Postgres, SQLite} {
+		migrations, err := Migrations(dialect)
+
+		assert.Equal(t, nil, err)
+		assert.NotEqual(t, 0, len(migrations))
+		for i, migration := range migrations {
+			assert.Equal(t, i+1, migration.Version)
+			assert.NotEqual(t, "", migration.Up)
+			assert.NotEqual(t, "", migration.Down)
+		}
+		assert.Equal(t, "create_category_and_product", migrations[0].Name)
+	}
+}
+
+func TestDialectsShareMigrationNames(t *testing.T) {
+	postgres, _ := Migrations(Postgres)
+	sqlite, _ := Migrations(SQLite)
+
+	assert.Equal(t, len(postgres), len(sqlite))
+	for i := range postgres {
+		assert.Equal(t, postgres[i].Name, sqlite[i].Name)
+	}
+}
+
+func TestMigrateSQLiteUpAndDown(t *testing.T) {
+	db, err := SQLite.Open(":memory:")
+	assert.Equal(t, nil, err)
+	defer db.Close()
+
+	assert.Equal(t, nil, Migrate(db, SQLite))
+	statuses, _ := Status(db, SQLite)
+	for _, status := range statuses {
+		assert.Equal(t, true, status.Applied)
+	}
+	_, err = db.Exec("INSERT INTO product(name, category_id) VALUES ('Meja', 42)")
+	assert.NotEqual(t, nil, err)
+
+	assert.Equal(t, nil, MigrateDown(db, SQLite, len(statuses)))
+	statuses, _ = Status(db, SQLite)
+	for _, status := range statuses {
+		assert.Equal(t, false, status.Applied)
+	}
+}
+
+func TestOpenDbLeavesTheSchemaAlone(t *testing.T) {
+	config := &helpers.DBConfig{Connection: "sqlite", URI: ":memory:", AutoMigrate: true}
+
+	db, dialect := OpenDb(config)
+	defer db.Close()
+	statuses, err := Status(db, dialect)
+	assert.Equal(t, nil, err)
+	for _, status := range statuses {
+		assert.Equal(t, false, status.Applied)
+	}
+
+	migrated, dialect := ConnectToDb(config)
+	defer migrated.Close()
+	statuses, _ = Status(migrated, dialect)
+	assert.Equal(t, true, statuses[len(statuses)-1].Applied)
+}
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS category
+(
+    id   SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS product
+(
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    category_id INT          NOT NULL REFERENCES category (id)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id           SERIAL PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL,
+    prefix       VARCHAR(16)  NOT NULL,
+    key_hash     CHAR(64)     NOT NULL UNIQUE,
+    scopes       TEXT[]       NOT NULL,
+    expires_at   TIMESTAMPTZ  NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
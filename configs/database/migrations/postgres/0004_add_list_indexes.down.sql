@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS product_category_id_id_idx;
+DROP INDEX IF EXISTS product_name_id_idx;
+DROP INDEX IF EXISTS category_name_id_idx;
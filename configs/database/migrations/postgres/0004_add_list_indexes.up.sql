@@ -0,0 +1,4 @@
+-- (column, id) indexes let keyset pages seek straight to the cursor.
+CREATE INDEX IF NOT EXISTS category_name_id_idx ON category (name, id);
+CREATE INDEX IF NOT EXISTS product_name_id_idx ON product (name, id);
+CREATE INDEX IF NOT EXISTS product_category_id_id_idx ON product (category_id, id);
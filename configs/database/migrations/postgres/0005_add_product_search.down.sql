@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS product_search_vector_idx;
+DROP TRIGGER IF EXISTS category_search_vector_refresh ON category;
+DROP FUNCTION IF EXISTS category_search_vector_refresh();
+DROP TRIGGER IF EXISTS product_search_vector_refresh ON product;
+DROP FUNCTION IF EXISTS product_search_vector_refresh();
+DROP FUNCTION IF EXISTS product_search_vector(TEXT, TEXT);
+ALTER TABLE product DROP COLUMN IF EXISTS search_vector;
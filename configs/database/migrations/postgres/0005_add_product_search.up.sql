@@ -0,0 +1,48 @@
+-- Full-text search over product and category names. search_vector weighs the
+-- product name (A) above its category name (B) and is kept current by the
+-- triggers below, on product writes and on category renames.
+ALTER TABLE product ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+CREATE OR REPLACE FUNCTION product_search_vector(product_name TEXT, category_name TEXT) RETURNS TSVECTOR AS
+$$
+SELECT setweight(to_tsvector('simple', coalesce(product_name, '')), 'A') ||
+       setweight(to_tsvector('simple', coalesce(category_name, '')), 'B')
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION product_search_vector_refresh() RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.search_vector := product_search_vector(NEW.name, (SELECT name FROM category WHERE id = NEW.category_id));
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS product_search_vector_refresh ON product;
+CREATE TRIGGER product_search_vector_refresh
+    BEFORE INSERT OR UPDATE OF name, category_id
+    ON product
+    FOR EACH ROW
+EXECUTE PROCEDURE product_search_vector_refresh();
+
+CREATE OR REPLACE FUNCTION category_search_vector_refresh() RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE product SET search_vector = product_search_vector(name, NEW.name) WHERE category_id = NEW.id;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS category_search_vector_refresh ON category;
+CREATE TRIGGER category_search_vector_refresh
+    AFTER UPDATE OF name
+    ON category
+    FOR EACH ROW
+EXECUTE PROCEDURE category_search_vector_refresh();
+
+UPDATE product
+SET search_vector = product_search_vector(product.name, category.name)
+FROM category
+WHERE category.id = product.category_id
+  AND product.search_vector IS NULL;
+
+CREATE INDEX IF NOT EXISTS product_search_vector_idx ON product USING GIN (search_vector);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS category
+(
+    id   INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS product
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        VARCHAR(255) NOT NULL,
+    category_id INTEGER      NOT NULL REFERENCES category (id)
+);
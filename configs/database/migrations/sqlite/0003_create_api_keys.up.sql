@@ -0,0 +1,12 @@
+-- scopes holds a JSON array of strings, SQLite having no array type.
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    name         VARCHAR(255) NOT NULL,
+    prefix       VARCHAR(16)  NOT NULL,
+    key_hash     CHAR(64)     NOT NULL UNIQUE,
+    scopes       TEXT         NOT NULL,
+    expires_at   TIMESTAMP    NOT NULL,
+    last_used_at TIMESTAMP,
+    created_at   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
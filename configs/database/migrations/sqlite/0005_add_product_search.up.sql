@@ -0,0 +1,4 @@
+-- SQLite has no tsvector. ProductRepositoryImpl.Search narrows the rows with
+-- LIKE and ranks them in Go instead, so there is nothing to create; the
+-- version is kept to number migrations the same on every backend.
+SELECT 1;
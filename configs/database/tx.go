@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"task-one/helpers"
+)
+
+type SQLTxManager struct {
+	DB      *sql.DB
+	Dialect Dialect
+}
+
+func NewTxManager(db *sql.DB, dialect Dialect) helpers.TxManager {
+	return &SQLTxManager{DB: db, Dialect: dialect}
+}
+
+// Begin starts a *Tx, which the SQL repositories expect as their helpers.Tx.
+func (manager *SQLTxManager) Begin(ctx context.Context) (helpers.Tx, error) {
+	tx, err := manager.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, Dialect: manager.Dialect}, nil
+}
+
+// Tx is a *sql.Tx rebinding the placeholders of every statement for its
+// dialect, so repositories keep a single text per query.
+type Tx struct {
+	*sql.Tx
+	helpers.CommitHooks
+	Dialect Dialect
+}
+
+func (tx *Tx) Commit() error {
+	err := tx.Tx.Commit()
+	if err != nil {
+		tx.RunRollback()
+		return err
+	}
+	tx.Run()
+	return nil
+}
+
+func (tx *Tx) Rollback() error {
+	err := tx.Tx.Rollback()
+	tx.RunRollback()
+	return err
+}
+
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.QueryContext(ctx, tx.Dialect.Rebind(query), args...)
+}
+
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRowContext(ctx, tx.Dialect.Rebind(query), args...)
+}
+
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.ExecContext(ctx, tx.Dialect.Rebind(query), args...)
+}
+
+// DBSystem names the backend in the spans of its statements.
+func (tx *Tx) DBSystem() string {
+	return tx.Dialect.System()
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"testing"
+)
+
+func TestTxRunsHooksOnlyAfterCommit(t *testing.T) {
+	db, err := SQLite.Open(":memory:")
+	assert.Equal(t, nil, err)
+	defer db.Close()
+	manager := NewTxManager(db, SQLite)
+	ran, rolledBack := 0, 0
+
+	tx, _ := manager.Begin(context.Background())
+	tx.AfterCommit(func() { ran++ })
+	tx.AfterRollback(func() { rolledBack++ })
+	assert.Equal(t, nil, tx.Rollback())
+	assert.Equal(t, 0, ran)
+	assert.Equal(t, 1, rolledBack)
+
+	tx, _ = manager.Begin(context.Background())
+	tx.AfterCommit(func() { ran++ })
+	tx.AfterRollback(func() { rolledBack++ })
+	assert.Equal(t, nil, tx.Commit())
+	assert.Equal(t, 1, ran)
+	assert.Equal(t, 1, rolledBack)
+
+	// A commit that fails runs the rollback hooks instead.
+	tx, _ = manager.Begin(context.Background())
+	tx.AfterCommit(func() { ran++ })
+	tx.AfterRollback(func() { rolledBack++ })
+	tx.(*Tx).Tx.Rollback()
+	assert.NotEqual(t, nil, tx.Commit())
+	assert.Equal(t, 1, ran)
+	assert.Equal(t, 2, rolledBack)
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type contextKey struct{}
+
+// New returns a logger writing JSON lines to stdout at level, one of debug,
+// info, warn or error. Every record logged through the *Context methods gets
+// the request_id and trace_id found in the context, if any.
+func New(level string) *slog.Logger {
+	return newLogger(os.Stdout, level)
+}
+
+func newLogger(writer io.Writer, level string) *slog.Logger {
+	handler := slog.NewJSONHandler(writer, &slog.HandlerOptions{Level: parseLevel(level)})
+	return slog.New(contextHandler{handler})
+}
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestId)
+}
+
+func RequestIdFromContext(ctx context.Context) string {
+	requestId, _ := ctx.Value(contextKey{}).(string)
+	return requestId
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (handler contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestId := RequestIdFromContext(ctx); requestId != "" {
+		record.AddAttrs(slog.String("request_id", requestId))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()))
+	}
+	return handler.Handler.Handle(ctx, record)
+}
+
+func (handler contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{handler.Handler.WithAttrs(attrs)}
+}
+
+func (handler contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{handler.Handler.WithGroup(name)}
+}
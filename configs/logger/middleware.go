@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/julienschmidt/httprouter"
+	"log/slog"
+	"net/http"
+	"task-one/helpers"
+	"time"
+)
+
+const (
+	RequestIdHeader = "X-Request-ID"
+	maxRequestIdLen = 128
+)
+
+// Middleware gives every request an id, taken from its X-Request-ID header or
+// generated, stores it in the request context and echoes it in the response
+// header. Once next is done it writes one access log line for the request.
+func Middleware(log *slog.Logger, router *httprouter.Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		start := time.Now()
+		requestId := request.Header.Get(RequestIdHeader)
+		if !validRequestId(requestId) {
+			requestId = newRequestId()
+		}
+
+		writer.Header().Set(RequestIdHeader, requestId)
+		ctx := WithRequestId(request.Context(), requestId)
+		request = request.WithContext(ctx)
+		recorder := helpers.NewResponseRecorder(writer)
+
+		next.ServeHTTP(recorder, request)
+
+		log.InfoContext(ctx, "request",
+			slog.String("method", request.Method),
+			slog.String("route", helpers.RoutePattern(router, request)),
+			slog.String("path", request.URL.Path),
+			slog.Int("status", recorder.Status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", recorder.Bytes),
+		)
+	})
+}
+
+// validRequestId accepts a client supplied id only if it is short and
+// printable ASCII, so it can be logged and echoed safely.
+func validRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLen {
+		return false
+	}
+	for _, c := range requestId {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestId() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
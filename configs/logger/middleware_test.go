@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-playground/assert/v2"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// serve runs request through the middleware and returns the response with the
+// log line written by the handler and the access log line.
+func serve(request *http.Request) (*httptest.ResponseRecorder, map[string]interface{}, map[string]interface{}) {
+	var output bytes.Buffer
+	log := newLogger(&output, "info")
+	router := httprouter.New()
+	router.GET("/products/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		log.InfoContext(request.Context(), "handling")
+		writer.Write([]byte("ok"))
+	})
+
+	recorder := httptest.NewRecorder()
+	Middleware(log, router, router).ServeHTTP(recorder, request)
+
+	lines := bytes.Split(bytes.TrimSpace(output.Bytes()), []byte("\n"))
+	var accessLog map[string]interface{}
+	json.Unmarshal(lines[len(lines)-1], &accessLog)
+
+	var handlerLog map[string]interface{}
+	json.Unmarshal(lines[0], &handlerLog)
+	return recorder, handlerLog, accessLog
+}
+
+func TestMiddlewarePropagatesRequestId(t *testing.T) {
+	request := httptest.NewRequest("GET", "/products/7", nil)
+	request.Header.Set(RequestIdHeader, "abc-123")
+
+	recorder, handlerLog, accessLog := serve(request)
+
+	assert.Equal(t, "abc-123", recorder.Header().Get(RequestIdHeader))
+	assert.Equal(t, "abc-123", handlerLog["request_id"])
+	assert.Equal(t, "abc-123", accessLog["request_id"])
+	assert.Equal(t, "/products/:id", accessLog["route"])
+	assert.Equal(t, float64(200), accessLog["status"])
+	assert.Equal(t, float64(2), accessLog["bytes"])
+}
+
+func TestMiddlewareGeneratesRequestId(t *testing.T) {
+	request := httptest.NewRequest("GET", "/products/7", nil)
+	request.Header.Set(RequestIdHeader, "has spaces")
+
+	recorder, handlerLog, accessLog := serve(request)
+
+	assert.Equal(t, 32, len(recorder.Header().Get(RequestIdHeader)))
+	assert.Equal(t, recorder.Header().Get(RequestIdHeader), handlerLog["request_id"])
+	assert.Equal(t, recorder.Header().Get(RequestIdHeader), accessLog["request_id"])
+}
@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// AsyncMailer sends mail through Mailer in the background, so callers do not
+// wait on the SMTP round trip. It keeps count of the mails still in flight, so
+// shutdown can wait for them instead of dropping them.
+type AsyncMailer struct {
+	Mailer Mailer
+	Logger *slog.Logger
+	wg     sync.WaitGroup
+}
+
+func NewAsyncMailer(mailer Mailer, logger *slog.Logger) *AsyncMailer {
+	return &AsyncMailer{Mailer: mailer, Logger: logger}
+}
+
+// SendMail queues the mail and returns at once. Failures are only logged,
+// since the caller has moved on by the time they happen. The mail keeps the
+// values of ctx, such as the trace, but not its cancellation, so it still goes
+// out once the request that queued it is done.
+func (m *AsyncMailer) SendMail(ctx context.Context, to []string, cc []string, subject, message string) error {
+	ctx = context.WithoutCancel(ctx)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		err := m.Mailer.SendMail(ctx, to, cc, subject, message)
+		if err != nil {
+			m.Logger.ErrorContext(ctx, "failed to send mail", slog.String("subject", subject), slog.String("error", err.Error()))
+		}
+	}()
+	return nil
+}
+
+// Wait blocks until every queued mail has been sent or ctx is done, in which
+// case the mails still pending are abandoned and ctx's error is returned.
+func (m *AsyncMailer) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type slowMailer struct {
+	delay time.Duration
+	sent  chan string
+}
+
+func (m *slowMailer) SendMail(ctx context.Context, to []string, cc []string, subject, message string) error {
+	time.Sleep(m.delay)
+	m.sent <- subject
+	return nil
+}
+
+func TestAsyncMailerWaitsForPendingMail(t *testing.T) {
+	mailer := &slowMailer{delay: 10 * time.Millisecond, sent: make(chan string, 1)}
+	async := NewAsyncMailer(mailer, slog.Default())
+
+	async.SendMail(context.Background(), []string{"a@mail.com"}, nil, "hello", "world")
+	err := async.Wait(context.Background())
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hello", <-mailer.sent)
+}
+
+func TestAsyncMailerWaitGivesUpAtDeadline(t *testing.T) {
+	mailer := &slowMailer{delay: time.Second, sent: make(chan string, 1)}
+	async := NewAsyncMailer(mailer, slog.Default())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	async.SendMail(context.Background(), []string{"a@mail.com"}, nil, "hello", "world")
+
+	assert.Equal(t, context.DeadlineExceeded, async.Wait(ctx))
+}
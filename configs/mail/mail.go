@@ -1,35 +1,82 @@
 package mail
 
 import (
+	"context"
 	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"net"
 	"net/smtp"
 	"strings"
+	"task-one/configs/metrics"
+	"task-one/configs/tracing"
 	"task-one/helpers"
 )
 
 type Mailer interface {
-	SendMail(to []string, cc []string, subject, message string) error
+	SendMail(ctx context.Context, to []string, cc []string, subject, message string) error
 }
 
 type SMTPMailer struct {
+	Config *helpers.MailConfig
 }
 
-func (g *SMTPMailer) SendMail(to []string, cc []string, subject, message string) error {
-	env := helpers.GetConfig()
-	body := "From: " + env.Mail.SenderName + "\n" +
+func NewSMTPMailer(config *helpers.MailConfig) *SMTPMailer {
+	return &SMTPMailer{Config: config}
+}
+
+func (g *SMTPMailer) SendMail(ctx context.Context, to []string, cc []string, subject, message string) (err error) {
+	ctx, span := tracing.Start(ctx, "smtp SendMail",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("mail.recipients", len(to)+len(cc))),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	config := g.Config
+	body := "From: " + config.SenderName + "\n" +
 		"To: " + strings.Join(to, ",") + "\n" +
 		"Cc: " + strings.Join(cc, ",") + "\n" +
 		"Subject: " + subject + "\n\n" +
 		message
 
-	auth := smtp.PlainAuth("", env.Mail.AuthEmail, env.Mail.AuthPassword, env.Mail.SmtpHost)
-	smtpAddr := fmt.Sprintf("%s:%d", env.Mail.SmtpHost, env.Mail.SmtpPort)
+	auth := smtp.PlainAuth("", config.AuthEmail, config.AuthPassword, config.SmtpHost)
+	smtpAddr := fmt.Sprintf("%s:%d", config.SmtpHost, config.SmtpPort)
 
-	err := smtp.SendMail(smtpAddr, auth, env.Mail.AuthEmail, append(to, cc...), []byte(body))
+	err = smtp.SendMail(smtpAddr, auth, config.AuthEmail, append(to, cc...), []byte(body))
 	if err != nil {
+		metrics.MailSent.WithLabelValues(metrics.MailFailure).Inc()
 		return err
 	}
+	metrics.MailSent.WithLabelValues(metrics.MailSuccess).Inc()
 
 	return nil
 
 }
+
+// Ping opens a connection to the SMTP host and issues a NOOP, checking that the
+// server is reachable without sending anything.
+func (g *SMTPMailer) Ping(ctx context.Context) error {
+	smtpAddr := fmt.Sprintf("%s:%d", g.Config.SmtpHost, g.Config.SmtpPort)
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", smtpAddr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, g.Config.SmtpHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	err = client.Noop()
+	if err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"task-one/helpers"
+)
+
+// FieldFunc returns the value of a sortable field of row, either an int or a
+// string.
+type FieldFunc[T any] func(row T, field string) interface{}
+
+// List sorts the already filtered rows by query and cuts out the requested
+// page, following the same rules as the SQL repositories: an offset page
+// reports the total, a keyset page reports the cursor of the next one. Every
+// query is sorted by id last, so "id" must be one of the fields.
+func List[T any](rows []T, query helpers.ListQuery, field FieldFunc[T]) ([]T, helpers.PageInfo) {
+	pageInfo := helpers.PageInfo{}
+	sorted := append([]T(nil), rows...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for _, sortField := range query.Sort {
+			order := compare(field(sorted[i], sortField.Field), field(sorted[j], sortField.Field))
+			if order != 0 {
+				return (order < 0) != sortField.Desc
+			}
+		}
+		return false
+	})
+
+	if !query.CursorMode {
+		pageInfo.Total = len(sorted)
+		return window(sorted, query.Offset(), query.Limit), pageInfo
+	}
+
+	start := 0
+	if query.Cursor != nil {
+		start = len(sorted)
+		for i, row := range sorted {
+			if afterCursor(row, query, field) {
+				start = i
+				break
+			}
+		}
+	}
+
+	page := window(sorted, start, query.Limit+1)
+	if len(page) > query.Limit {
+		page = page[:query.Limit]
+		last := page[len(page)-1]
+		pageInfo.NextCursor = query.NextCursor(format(field(last, query.Sort[0].Field)), field(last, "id").(int))
+	}
+	return page, pageInfo
+}
+
+// ContainsFold reports whether substr is within s, ignoring case, as ILIKE
+// does for a helpers.ContainsPattern.
+func ContainsFold(s string, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func afterCursor[T any](row T, query helpers.ListQuery, field FieldFunc[T]) bool {
+	id := field(row, "id").(int)
+	order := 0
+	if len(query.Sort) > 1 {
+		value := field(row, query.Sort[0].Field)
+		order = compare(value, parse(query.Cursor.Value, value))
+	}
+	if order == 0 {
+		order = compare(id, query.Cursor.Id)
+	}
+
+	if query.Sort[0].Desc {
+		return order < 0
+	}
+	return order > 0
+}
+
+func window[T any](rows []T, offset int, limit int) []T {
+	if offset >= len(rows) {
+		return nil
+	}
+	end := offset + limit
+	if end > len(rows) {
+		end = len(rows)
+	}
+	return rows[offset:end]
+}
+
+func compare(a interface{}, b interface{}) int {
+	switch a := a.(type) {
+	case int:
+		return a - b.(int)
+	case string:
+		return strings.Compare(a, b.(string))
+	}
+	return 0
+}
+
+// parse reads a cursor value as the same type as like.
+func parse(value string, like interface{}) interface{} {
+	if _, ok := like.(int); ok {
+		number, _ := strconv.Atoi(value)
+		return number
+	}
+	return value
+}
+
+func format(value interface{}) string {
+	if number, ok := value.(int); ok {
+		return strconv.Itoa(number)
+	}
+	return value.(string)
+}
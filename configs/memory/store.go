@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"task-one/helpers"
+)
+
+// Store is the TxManager of the in-memory repositories. Begin holds the store
+// lock until the transaction ends, so transactions run one after another and
+// each sees exactly the committed writes of those before it. That is plenty
+// for local development and tests, which is all this backend is meant for.
+type Store struct {
+	mu sync.Mutex
+}
+
+func NewStore() *Store {
+	return &Store{}
+}
+
+func (store *Store) Begin(ctx context.Context) (helpers.Tx, error) {
+	store.mu.Lock()
+	return &Tx{store: store}, nil
+}
+
+// Tx records how to undo each write made through it, so Rollback can restore
+// the repositories in reverse order.
+type Tx struct {
+	helpers.CommitHooks
+	store *Store
+	undo  []func()
+	done  bool
+}
+
+func (tx *Tx) Commit() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	tx.undo = nil
+	tx.store.mu.Unlock()
+	// The hooks run once the store is unlocked, so they may start
+	// transactions of their own.
+	tx.Run()
+	return nil
+}
+
+func (tx *Tx) Rollback() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+	tx.undo = nil
+	tx.store.mu.Unlock()
+	tx.RunRollback()
+	return nil
+}
+
+// OnRollback registers undo to run if tx is rolled back. It panics when tx
+// does not come from a Store, which means the repositories were wired to the
+// wrong backend.
+func OnRollback(tx helpers.Tx, undo func()) {
+	memoryTx, ok := tx.(*Tx)
+	if !ok {
+		panic(fmt.Sprintf("memory: %T is not an in-memory transaction", tx))
+	}
+	memoryTx.undo = append(memoryTx.undo, undo)
+}
+
+// Driver is the DB_DRIVER value selecting the in-memory repositories.
+const Driver = "memory"
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"net/url"
+	"task-one/helpers"
+	"testing"
+)
+
+type row struct {
+	Id   int
+	Name string
+}
+
+func rowField(row row, field string) interface{} {
+	if field == "name" {
+		return row.Name
+	}
+	return row.Id
+}
+
+func TestRollbackUndoesWritesInReverse(t *testing.T) {
+	store := NewStore()
+	values := map[string]int{"a": 1}
+
+	tx, _ := store.Begin(context.Background())
+	values["a"] = 2
+	OnRollback(tx, func() { values["a"] = 1 })
+	values["b"] = 3
+	OnRollback(tx, func() { delete(values, "b") })
+	tx.Rollback()
+
+	assert.Equal(t, map[string]int{"a": 1}, values)
+	assert.NotEqual(t, nil, tx.Commit())
+
+	// The store is free again once the transaction ended.
+	next, _ := store.Begin(context.Background())
+	assert.Equal(t, nil, next.Commit())
+}
+
+func TestTxHooks(t *testing.T) {
+	store := NewStore()
+	var ran []string
+
+	tx, _ := store.Begin(context.Background())
+	tx.AfterCommit(func() { ran = append(ran, "committed") })
+	tx.AfterRollback(func() {
+		next, _ := store.Begin(context.Background())
+		next.Rollback()
+		ran = append(ran, "rolled back")
+	})
+	tx.Rollback()
+
+	tx, _ = store.Begin(context.Background())
+	tx.AfterCommit(func() { ran = append(ran, "first") })
+	tx.AfterRollback(func() { ran = append(ran, "rolled back") })
+	tx.AfterCommit(func() {
+		// The store is already unlocked when the hooks run.
+		next, _ := store.Begin(context.Background())
+		next.Commit()
+		ran = append(ran, "second")
+	})
+	assert.Equal(t, 1, len(ran))
+	tx.Commit()
+
+	assert.Equal(t, []string{"rolled back", "first", "second"}, ran)
+}
+
+func TestListOffsetPage(t *testing.T) {
+	rows := []row{{1, "Gamma"}, {2, "Alpha"}, {3, "Beta"}}
+	query, _ := helpers.ParseListQuery(url.Values{"sort": {"-name"}, "limit": {"2"}, "page": {"2"}}, []string{"id", "name"}, nil)
+
+	page, pageInfo := List(rows, query, rowField)
+
+	assert.Equal(t, []row{{2, "Alpha"}}, page)
+	assert.Equal(t, 3, pageInfo.Total)
+}
+
+func TestListKeysetPages(t *testing.T) {
+	rows := []row{{1, "Beta"}, {2, "Alpha"}, {3, "Beta"}, {4, "Gamma"}}
+	sortable := []string{"id", "name"}
+
+	first, _ := helpers.ParseListQuery(url.Values{"sort": {"name"}, "limit": {"2"}, "cursor": {""}}, sortable, nil)
+	page, pageInfo := List(rows, first, rowField)
+	assert.Equal(t, []row{{2, "Alpha"}, {1, "Beta"}}, page)
+
+	second, _ := helpers.ParseListQuery(url.Values{"sort": {"name"}, "limit": {"2"}, "cursor": {pageInfo.NextCursor}}, sortable, nil)
+	page, pageInfo = List(rows, second, rowField)
+	assert.Equal(t, []row{{3, "Beta"}, {4, "Gamma"}}, page)
+	assert.Equal(t, "", pageInfo.NextCursor)
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"task-one/helpers"
+	"time"
+)
+
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheStale = "stale"
+
+	MailSuccess = "success"
+	MailFailure = "failure"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_requests_total",
+		Help: "Cache lookups by cache and result (hit, stale or miss).",
+	}, []string{"cache", "result"})
+
+	MailSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mail_sent_total",
+		Help: "Mails handed to the SMTP server by result (success or failure).",
+	}, []string{"result"})
+)
+
+// RegisterDB exposes the sql.DBStats of db, such as open, in-use and idle
+// connections and time spent waiting for one, labelled with name. Registering
+// another pool under the same name replaces the previous one.
+func RegisterDB(db *sql.DB, name string) {
+	collector := collectors.NewDBStatsCollector(db, name)
+	err := prometheus.Register(collector)
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		prometheus.Unregister(registered.ExistingCollector)
+		err = prometheus.Register(collector)
+	}
+	helpers.PanicIfError(err)
+}
+
+// RegisterRoute serves every registered metric on /metrics.
+func RegisterRoute(router *httprouter.Router) {
+	router.Handler("GET", "/metrics", promhttp.Handler())
+}
+
+// Middleware counts and times every request handled by router, labelled with
+// the route pattern it matched, e.g. /products/:id, so ids do not blow up the
+// number of series. Requests matching no route are labelled "unmatched".
+func Middleware(router *httprouter.Router) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		start := time.Now()
+		recorder := helpers.NewResponseRecorder(writer)
+
+		router.ServeHTTP(recorder, request)
+
+		status := strconv.Itoa(recorder.Status)
+		route := helpers.RoutePattern(router, request)
+		httpRequests.WithLabelValues(request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(request.Method, route, status).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"database/sql"
+	"github.com/go-playground/assert/v2"
+	"github.com/julienschmidt/httprouter"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	_ "modernc.org/sqlite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddlewareLabelsRequestsByRoute(t *testing.T) {
+	router := httprouter.New()
+	router.GET("/products/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		writer.WriteHeader(404)
+	})
+	handler := Middleware(router)
+
+	for _, path := range []string{"/products/1", "/products/products", "/nothing"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/products/:id", "404")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(httpRequests.WithLabelValues("GET", "unmatched", "404")))
+}
+
+func TestRegisterDBTwice(t *testing.T) {
+	first, _ := sql.Open("sqlite", ":memory:")
+	second, _ := sql.Open("sqlite", ":memory:")
+	defer first.Close()
+	defer second.Close()
+
+	RegisterDB(first, "test")
+	RegisterDB(second, "test")
+}
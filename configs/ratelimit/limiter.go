@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"task-one/helpers"
+	"time"
+)
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the oldest counted request leaves the window.
+	Reset time.Duration
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit helpers.RateLimit) (Result, error)
+}
+
+// slidingWindowScript keeps one sorted set member per accepted request, scored
+// by its time in milliseconds. Members older than the window are dropped
+// before counting, so the limit holds over any window-long span rather than
+// per fixed bucket.
+const slidingWindowScript = `
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+local reset = window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, limit - count, reset}
+`
+
+// Scripter runs Lua scripts on Redis, as redis.RedisClient does.
+type Scripter interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+type RedisLimiter struct {
+	Redis Scripter
+}
+
+func NewRedisLimiter(rdb Scripter) Limiter {
+	return &RedisLimiter{Redis: rdb}
+}
+
+func (limiter *RedisLimiter) Allow(ctx context.Context, key string, limit helpers.RateLimit) (Result, error) {
+	now := time.Now().UnixMilli()
+	member := fmt.Sprintf("%d-%d", now, rand.Int63())
+
+	reply, err := limiter.Redis.Eval(ctx, slidingWindowScript, []string{key},
+		now, limit.Window.Milliseconds(), limit.Requests, member)
+	if err != nil {
+		return Result{}, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	reset, _ := values[2].(int64)
+
+	return Result{
+		Allowed:   allowed == 1,
+		Limit:     limit.Requests,
+		Remaining: int(remaining),
+		Reset:     time.Duration(reset) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"task-one/exception"
+	"task-one/helpers"
+	"time"
+)
+
+// KeyFunc names the client a request counts against, e.g. "user:42".
+type KeyFunc func(request *http.Request) string
+
+type Middleware interface {
+	// Limit wraps handle with the limit configured for route, which is written
+	// as "<METHOD> <path>" the same way the route was registered.
+	Limit(route string, handle httprouter.Handle) httprouter.Handle
+}
+
+type MiddlewareImpl struct {
+	Limiter Limiter
+	Config  *helpers.RateLimitConfig
+	Key     KeyFunc
+	Logger  *slog.Logger
+}
+
+func NewMiddleware(limiter Limiter, config *helpers.RateLimitConfig, key KeyFunc, logger *slog.Logger) Middleware {
+	return &MiddlewareImpl{Limiter: limiter, Config: config, Key: key, Logger: logger}
+}
+
+// Limit answers 429 once the client has used up the route's limit. Every
+// limited response carries the RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy headers. When the limiter itself fails
+// the request is let through, so a Redis outage does not take the API down.
+func (middleware *MiddlewareImpl) Limit(route string, handle httprouter.Handle) httprouter.Handle {
+	limit, ok := middleware.Config.Routes[route]
+	if !ok {
+		limit = middleware.Config.Default
+	}
+	if !middleware.Config.Enabled {
+		return handle
+	}
+	policy := strconv.Itoa(limit.Requests) + ";w=" + strconv.Itoa(seconds(limit.Window))
+
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		result, err := middleware.Limiter.Allow(request.Context(), "ratelimit:"+route+":"+middleware.Key(request), limit)
+		if err != nil {
+			middleware.Logger.WarnContext(request.Context(), "rate limiter unavailable",
+				slog.String("route", route), slog.Any("error", err))
+			handle(writer, request, params)
+			return
+		}
+
+		reset := strconv.Itoa(seconds(result.Reset))
+		writer.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		writer.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		writer.Header().Set("RateLimit-Reset", reset)
+		writer.Header().Set("RateLimit-Policy", policy)
+
+		if !result.Allowed {
+			writer.Header().Set("Retry-After", reset)
+			exception.ErrorHandler(writer, request, exception.NewTooManyRequestsError("rate limit exceeded, retry in "+reset+"s"))
+			return
+		}
+		handle(writer, request, params)
+	}
+}
+
+type unlimited struct{}
+
+// NewUnlimitedMiddleware returns a Middleware that never limits.
+func NewUnlimitedMiddleware() Middleware {
+	return unlimited{}
+}
+
+func (unlimited) Limit(route string, handle httprouter.Handle) httprouter.Handle {
+	return handle
+}
+
+func seconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
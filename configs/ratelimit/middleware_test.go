@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"github.com/go-playground/assert/v2"
+	"github.com/julienschmidt/httprouter"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"task-one/helpers"
+	"testing"
+	"time"
+)
+
+// countingLimiter allows the first limit.Requests calls per key.
+type countingLimiter struct {
+	counts map[string]int
+	err    error
+}
+
+func (limiter *countingLimiter) Allow(ctx context.Context, key string, limit helpers.RateLimit) (Result, error) {
+	if limiter.err != nil {
+		return Result{}, limiter.err
+	}
+	limiter.counts[key]++
+	remaining := limit.Requests - limiter.counts[key]
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Result{
+		Allowed:   limiter.counts[key] <= limit.Requests,
+		Limit:     limit.Requests,
+		Remaining: remaining,
+		Reset:     1500 * time.Millisecond,
+	}, nil
+}
+
+var config = &helpers.RateLimitConfig{
+	Enabled: true,
+	Default: helpers.RateLimit{Requests: 5, Window: time.Minute},
+	Routes:  map[string]helpers.RateLimit{"POST /auth/login": {Requests: 2, Window: time.Minute}},
+}
+
+func serve(handle httprouter.Handle, remoteAddr string) *http.Response {
+	request := httptest.NewRequest("POST", "/auth/login", nil)
+	request.RemoteAddr = remoteAddr
+	recorder := httptest.NewRecorder()
+	handle(recorder, request, nil)
+	return recorder.Result()
+}
+
+func ok(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func byRemoteAddr(request *http.Request) string {
+	return request.RemoteAddr
+}
+
+func TestLimitUsesRouteLimit(t *testing.T) {
+	middleware := NewMiddleware(&countingLimiter{counts: map[string]int{}}, config, byRemoteAddr, slog.Default())
+	handle := middleware.Limit("POST /auth/login", ok)
+
+	first := serve(handle, "10.0.0.1")
+	assert.Equal(t, http.StatusNoContent, first.StatusCode)
+	assert.Equal(t, "2", first.Header.Get("RateLimit-Limit"))
+	assert.Equal(t, "1", first.Header.Get("RateLimit-Remaining"))
+	assert.Equal(t, "2", first.Header.Get("RateLimit-Reset"))
+	assert.Equal(t, "2;w=60", first.Header.Get("RateLimit-Policy"))
+
+	serve(handle, "10.0.0.1")
+	limited := serve(handle, "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, limited.StatusCode)
+	assert.Equal(t, "0", limited.Header.Get("RateLimit-Remaining"))
+	assert.Equal(t, "2", limited.Header.Get("Retry-After"))
+	assert.Equal(t, "application/problem+json", limited.Header.Get("Content-Type"))
+
+	other := serve(handle, "10.0.0.2")
+	assert.Equal(t, http.StatusNoContent, other.StatusCode)
+}
+
+func TestLimitFallsBackToDefault(t *testing.T) {
+	middleware := NewMiddleware(&countingLimiter{counts: map[string]int{}}, config, byRemoteAddr, slog.Default())
+
+	res := serve(middleware.Limit("GET /products", ok), "10.0.0.1")
+
+	assert.Equal(t, "5", res.Header.Get("RateLimit-Limit"))
+}
+
+func TestLimitFailsOpen(t *testing.T) {
+	middleware := NewMiddleware(&countingLimiter{err: errors.New("connection refused")}, config, byRemoteAddr, slog.Default())
+
+	res := serve(middleware.Limit("POST /auth/login", ok), "10.0.0.1")
+
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	assert.Equal(t, "", res.Header.Get("RateLimit-Limit"))
+}
+
+func TestLimitDisabled(t *testing.T) {
+	middleware := NewMiddleware(nil, &helpers.RateLimitConfig{}, byRemoteAddr, slog.Default())
+
+	res := serve(middleware.Limit("POST /auth/login", ok), "10.0.0.1")
+
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+}
@@ -4,51 +4,170 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/go-redis/redis/v8"
-	"log"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"strings"
+	"task-one/configs/tracing"
 	"task-one/helpers"
 	"time"
 )
 
+// Nil is the error of Get for a missing key.
+var Nil = redis.Nil
+
 type Redis interface {
-	Set(ctx context.Context, key string, value interface{}) error
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	// SetNX sets key like Set, but only when it does not exist yet, and tells
+	// whether it did.
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
 	Get(ctx context.Context, key string) (string, error)
+	Delete(ctx context.Context, keys ...string) error
+	// CompareAndDelete deletes key only while it still holds value.
+	CompareAndDelete(ctx context.Context, key string, value interface{}) error
+	DeleteByPrefix(ctx context.Context, prefix string) error
 }
 
 type RedisClient struct {
 	rdb *redis.Client
 }
 
-func InitRedis() *RedisClient {
-	env := helpers.GetConfig()
+func InitRedis(config *helpers.RedisConfig) *RedisClient {
 	client := redis.NewClient(&redis.Options{
-		Addr:     env.Redis.Host,
-		Password: env.Redis.Password,
-		DB:       env.Redis.Db,
+		Addr:     config.Host,
+		Password: config.Password,
+		DB:       config.Db,
 	})
 
-	res := client.Ping(context.Background())
-	log.Println(res)
+	err := client.Ping(context.Background()).Err()
+	if err != nil {
+		slog.Warn("redis is unreachable", slog.String("addr", config.Host), slog.String("error", err.Error()))
+	}
 
 	return &RedisClient{rdb: client}
 }
 
-func (r *RedisClient) Set(ctx context.Context, key string, value interface{}) error {
+func (r *RedisClient) Ping(ctx context.Context) error {
+	return r.rdb.Ping(ctx).Err()
+}
+
+func (r *RedisClient) Close() error {
+	return r.rdb.Close()
+}
+
+// Set stores value as JSON. It expires after ttl, or never when ttl is 0.
+func (r *RedisClient) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) (err error) {
+	ctx, span := startCommand(ctx, "SET", key)
+	defer func() { tracing.End(span, err) }()
+
 	data, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
 
-	err = r.rdb.Set(ctx, key, data, 10*time.Minute).Err()
+	err = r.rdb.Set(ctx, key, data, ttl).Err()
 	return err
 
 }
 
-func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
-	val, err := r.rdb.Get(ctx, key).Result()
+// SetNX is SET with NX, which makes it usable as a lock that lapses after ttl.
+func (r *RedisClient) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (ok bool, err error) {
+	ctx, span := startCommand(ctx, "SET NX", key)
+	defer func() { tracing.End(span, err) }()
+
+	data, err := json.Marshal(value)
 	if err != nil {
-		return "", err
+		return false, err
+	}
+	return r.rdb.SetNX(ctx, key, data, ttl).Result()
+}
+
+// compareAndDeleteScript checks and deletes in one step, so a lock that
+// lapsed and was taken by someone else is left alone.
+const compareAndDeleteScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+func (r *RedisClient) CompareAndDelete(ctx context.Context, key string, value interface{}) (err error) {
+	ctx, span := startCommand(ctx, "COMPARE DEL", key)
+	defer func() { tracing.End(span, err) }()
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = r.Eval(ctx, compareAndDeleteScript, []string{key}, string(data))
+	return err
+}
+
+func (r *RedisClient) Delete(ctx context.Context, keys ...string) (err error) {
+	ctx, span := startCommand(ctx, "DEL", strings.Join(keys, " "))
+	defer func() { tracing.End(span, err) }()
+
+	if len(keys) == 0 {
+		return nil
 	}
+	return r.rdb.Del(ctx, keys...).Err()
+}
 
+func (r *RedisClient) Get(ctx context.Context, key string) (val string, err error) {
+	ctx, span := startCommand(ctx, "GET", key)
+	defer func() {
+		// A miss is an answer, not a failure of the command.
+		if err == redis.Nil {
+			tracing.End(span, nil)
+			return
+		}
+		tracing.End(span, err)
+	}()
+
+	val, err = r.rdb.Get(ctx, key).Result()
+	if err == nil || err == redis.Nil {
+		span.SetAttributes(attribute.Bool("cache.hit", err == nil))
+	}
 	return val, err
+}
+
+// DeleteByPrefix removes every key starting with prefix. It walks the keyspace
+// with SCAN rather than KEYS so a large cache does not block the server.
+func (r *RedisClient) DeleteByPrefix(ctx context.Context, prefix string) (err error) {
+	ctx, span := startCommand(ctx, "SCAN+DEL", prefix+"*")
+	defer func() { tracing.End(span, err) }()
+
+	iter := r.rdb.Scan(ctx, 0, prefix+"*", 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.rdb.Del(ctx, keys...).Err()
+}
+
+// Eval runs a Lua script atomically on the server.
+func (r *RedisClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (result interface{}, err error) {
+	ctx, span := startCommand(ctx, "EVAL", strings.Join(keys, " "))
+	defer func() { tracing.End(span, err) }()
+
+	return r.rdb.Eval(ctx, script, keys, args...).Result()
+}
 
+func startCommand(ctx context.Context, command string, key string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "redis "+command,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemRedis,
+			semconv.DBOperation(command),
+			attribute.String("db.redis.key", key),
+		),
+	)
 }
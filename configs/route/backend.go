@@ -0,0 +1,50 @@
+package route
+
+import (
+	"database/sql"
+	"log/slog"
+	"task-one/auth"
+	"task-one/category"
+	"task-one/configs/database"
+	"task-one/configs/memory"
+	"task-one/helpers"
+	"task-one/product"
+)
+
+// backend is the set of repositories of one storage backend together with the
+// TxManager their transactions come from. DB is nil for the memory backend.
+type backend struct {
+	DB         *sql.DB
+	TxManager  helpers.TxManager
+	Users      auth.UserRepository
+	ApiKeys    auth.ApiKeyRepository
+	Categories category.CategoryRepository
+	Products   product.ProductRepository
+}
+
+// newBackend picks the repositories for config.Connection: the in-memory ones
+// for "memory", the SQL ones speaking the database.Dialect of the driver
+// otherwise.
+func newBackend(config *helpers.DBConfig, log *slog.Logger) backend {
+	if config.Connection == memory.Driver {
+		log.Warn("using the in-memory backend, data is lost on restart")
+		categories := category.NewCategoryMemoryRepository()
+		return backend{
+			TxManager:  memory.NewStore(),
+			Users:      auth.NewUserMemoryRepository(),
+			ApiKeys:    auth.NewApiKeyMemoryRepository(),
+			Categories: categories,
+			Products:   product.NewProductMemoryRepository(categories),
+		}
+	}
+
+	db, dialect := database.ConnectToDb(config)
+	return backend{
+		DB:         db,
+		TxManager:  database.NewTxManager(db, dialect),
+		Users:      auth.NewUserRepository(),
+		ApiKeys:    auth.NewApiKeyRepository(dialect),
+		Categories: category.NewCategoryRepository(dialect, log),
+		Products:   product.NewProductRepository(dialect, log),
+	}
+}
@@ -1,20 +1,100 @@
 package route
 
 import (
+	"context"
+	"database/sql"
 	"github.com/julienschmidt/httprouter"
+	"log/slog"
+	"net/http"
+	"task-one/auth"
 	"task-one/category"
+	"task-one/configs/cache"
 	"task-one/configs/database"
+	"task-one/configs/logger"
+	"task-one/configs/mail"
+	"task-one/configs/metrics"
+	"task-one/configs/ratelimit"
+	"task-one/configs/redis"
+	"task-one/configs/security"
+	"task-one/configs/tracing"
 	"task-one/exception"
+	"task-one/health"
+	"task-one/helpers"
 	"task-one/product"
 )
 
-func NewRouter() *httprouter.Router {
+// App is the router together with the resources it was built on, which have
+// to be released when the server stops.
+type App struct {
+	Router  *httprouter.Router
+	Handler http.Handler
+	DB      *sql.DB
+	Redis   *redis.RedisClient
+	Mailer  *mail.AsyncMailer
+}
+
+func NewApp(env *helpers.Config, log *slog.Logger) *App {
 	var Router *httprouter.Router = httprouter.New()
 
-	db := database.ConnectToDb()
-	category.RegisterRoute(Router, db)
-	product.RegisterRoute(Router, db)
+	rdb := redis.InitRedis(env.Redis)
+	backend := newBackend(env.DB, log)
+	categories, products := backend.Categories, backend.Products
+	// Without a cache the repositories are used as they are, so reads do not
+	// pay for the loader's locking and coalescing for nothing.
+	if env.Cache.Driver != cache.None {
+		// Only Postgres runs transactions side by side; SQLite and the memory
+		// store take them one at a time.
+		serial := env.DB.Connection != database.Postgres.Name()
+		loader := cache.NewLoader(cache.New(env.Cache, rdb), cache.NewLocker(env.Cache, rdb), backend.TxManager, serial, log)
+		categories = category.NewCategoryCachedRepository(categories, loader, env.Cache, log)
+		products = product.NewProductCachedRepository(products, categories, loader, env.Cache, log)
+	}
+	smtpMailer := mail.NewSMTPMailer(env.Mail)
+	mailer := mail.NewAsyncMailer(smtpMailer, log)
+	tokens := auth.NewTokenManager(env.JWT)
+	apiKeyService := auth.NewApiKeyService(backend.ApiKeys, backend.TxManager, log)
+	authMiddleware := auth.NewAuthMiddleware(tokens, apiKeyService)
+	// Every route is limited per IP before authentication, and the protected
+	// ones per user or API key as well.
+	limiter := ratelimit.NewMiddleware(ratelimit.NewRedisLimiter(rdb), env.RateLimit, auth.ClientKey, log)
+	principalLimiter := ratelimit.NewMiddleware(ratelimit.NewRedisLimiter(rdb), env.RateLimit, auth.PrincipalKey, log)
+
+	auth.RegisterRoute(Router, backend.TxManager, backend.Users, tokens, apiKeyService, authMiddleware, limiter, principalLimiter, log)
+	category.RegisterRoute(Router, backend.TxManager, categories, authMiddleware, limiter, principalLimiter, log)
+	product.RegisterRoute(Router, backend.TxManager, products, categories, mailer, authMiddleware, limiter, principalLimiter, log)
+
+	var checks []health.Check
+	if backend.DB != nil {
+		checks = append(checks, health.Check{Name: env.DB.Connection, Required: true, Probe: backend.DB.PingContext})
+		metrics.RegisterDB(backend.DB, "task_one")
+	}
+	checks = append(checks, health.Check{Name: "redis", Required: !env.Redis.Optional, Probe: rdb.Ping})
+	if env.Mail.HealthCheck {
+		checks = append(checks, health.Check{Name: "smtp", Probe: smtpMailer.Ping})
+	}
+	health.RegisterRoute(Router, checks...)
+
+	metrics.RegisterRoute(Router)
 
 	Router.PanicHandler = exception.ErrorHandler
-	return Router
+	return &App{Router: Router, Handler: logger.Middleware(log, Router, tracing.Middleware(Router, security.Middleware(env.Cors, env.Security, metrics.Middleware(Router)))), DB: backend.DB, Redis: rdb, Mailer: mailer}
+}
+
+// Close waits for pending mail until ctx is done, then closes the database
+// pool, if there is one, and the Redis client. It should only be called once
+// the HTTP server has stopped taking requests.
+func (app *App) Close(ctx context.Context) error {
+	mailErr := app.Mailer.Wait(ctx)
+	var dbErr error
+	if app.DB != nil {
+		dbErr = app.DB.Close()
+	}
+	redisErr := app.Redis.Close()
+
+	for _, err := range []error{mailErr, dbErr, redisErr} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,24 @@
+package security
+
+import (
+	"net/http"
+	"strconv"
+	"task-one/exception"
+)
+
+// BodyLimit caps the request body at maxBytes. A declared Content-Length over
+// the limit is rejected with 413 straight away; a body that only turns out to
+// be too long while it is read makes helpers.ReadFromRequestBody fail with
+// exception.PayloadTooLargeError.
+func BodyLimit(maxBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.ContentLength > maxBytes {
+			exception.ErrorHandler(writer, request,
+				exception.NewPayloadTooLargeError("request body exceeds "+strconv.FormatInt(maxBytes, 10)+" bytes"))
+			return
+		}
+
+		request.Body = http.MaxBytesReader(writer, request.Body, maxBytes)
+		next.ServeHTTP(writer, request)
+	})
+}
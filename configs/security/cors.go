@@ -0,0 +1,78 @@
+package security
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"task-one/helpers"
+)
+
+// Cors answers preflight requests from allowed origins itself and adds the
+// Access-Control-* headers to their other requests. Requests from origins that
+// are not allowed get no CORS headers, which makes the browser block them.
+// Without any allowed origin CORS is off and next is returned as is.
+func Cors(config *helpers.CorsConfig, next http.Handler) http.Handler {
+	if len(config.AllowedOrigins) == 0 {
+		return next
+	}
+	allowedMethods := strings.Join(config.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(config.AllowedHeaders, ", ")
+	exposedHeaders := strings.Join(config.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		// Every response depends on Origin, including those to requests
+		// without one, or a cache could serve them to a browser that sends it.
+		header := writer.Header()
+		header.Add("Vary", "Origin")
+		origin := request.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		allowOrigin, ok := allowedOrigin(config, origin)
+		if !ok {
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		header.Set("Access-Control-Allow-Origin", allowOrigin)
+		if config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if request.Method == http.MethodOptions && request.Header.Get("Access-Control-Request-Method") != "" {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", allowedMethods)
+			header.Set("Access-Control-Allow-Headers", allowedHeaders)
+			header.Set("Access-Control-Max-Age", maxAge)
+			writer.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if exposedHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", exposedHeaders)
+		}
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// allowedOrigin returns the Access-Control-Allow-Origin value for origin.
+// Browsers refuse "*" on credentialed requests, so the origin is echoed back
+// instead when credentials are allowed.
+func allowedOrigin(config *helpers.CorsConfig, origin string) (string, bool) {
+	for _, allowed := range config.AllowedOrigins {
+		if allowed == "*" {
+			if config.AllowCredentials {
+				return origin, true
+			}
+			return "*", true
+		}
+		if strings.EqualFold(allowed, origin) {
+			return origin, true
+		}
+	}
+	return "", false
+}
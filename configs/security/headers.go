@@ -0,0 +1,27 @@
+package security
+
+import (
+	"net/http"
+	"strconv"
+	"task-one/helpers"
+)
+
+// Headers sets the security headers of every response. The API serves JSON
+// only, so it forbids sniffing, framing and loading any subresource.
+func Headers(config *helpers.SecurityConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		header := writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("X-Frame-Options", "DENY")
+		header.Set("Referrer-Policy", "no-referrer")
+		header.Set("Cross-Origin-Resource-Policy", "same-site")
+		if config.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", config.ContentSecurityPolicy)
+		}
+		if config.HSTSMaxAge > 0 {
+			header.Set("Strict-Transport-Security", "max-age="+strconv.Itoa(int(config.HSTSMaxAge.Seconds()))+"; includeSubDomains")
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
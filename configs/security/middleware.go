@@ -0,0 +1,12 @@
+package security
+
+import (
+	"net/http"
+	"task-one/helpers"
+)
+
+// Middleware applies Headers, Cors and BodyLimit in that order, so even
+// preflight responses carry the security headers.
+func Middleware(cors *helpers.CorsConfig, security *helpers.SecurityConfig, next http.Handler) http.Handler {
+	return Headers(security, Cors(cors, BodyLimit(security.MaxBodyBytes, next)))
+}
@@ -0,0 +1,126 @@
+package security
+
+import (
+	"github.com/go-playground/assert/v2"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task-one/exception"
+	"task-one/helpers"
+	"testing"
+	"time"
+)
+
+var corsConfig = &helpers.CorsConfig{
+	AllowedOrigins: []string{"https://app.example.com"},
+	AllowedMethods: []string{"GET", "POST"},
+	AllowedHeaders: []string{"Authorization", "Content-Type"},
+	ExposedHeaders: []string{"X-Request-ID"},
+	MaxAge:         10 * time.Minute,
+}
+
+var securityConfig = &helpers.SecurityConfig{
+	HSTSMaxAge:            time.Hour,
+	ContentSecurityPolicy: "default-src 'none'",
+	MaxBodyBytes:          16,
+}
+
+type body struct {
+	Name string `json:"name"`
+}
+
+func handler() http.Handler {
+	return Middleware(corsConfig, securityConfig, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		err := helpers.ReadFromRequestBody(request, &body{})
+		if err != nil {
+			exception.ErrorHandler(writer, request, err)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+	}))
+}
+
+func serve(request *http.Request) *http.Response {
+	recorder := httptest.NewRecorder()
+	handler().ServeHTTP(recorder, request)
+	return recorder.Result()
+}
+
+func TestPreflightFromAllowedOrigin(t *testing.T) {
+	request := httptest.NewRequest("OPTIONS", "/products", nil)
+	request.Header.Set("Origin", "https://app.example.com")
+	request.Header.Set("Access-Control-Request-Method", "POST")
+
+	res := serve(request)
+
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	assert.Equal(t, "https://app.example.com", res.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", res.Header.Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type", res.Header.Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", res.Header.Get("Access-Control-Max-Age"))
+	assert.Equal(t, "nosniff", res.Header.Get("X-Content-Type-Options"))
+}
+
+func TestPreflightFromUnknownOrigin(t *testing.T) {
+	request := httptest.NewRequest("OPTIONS", "/products", nil)
+	request.Header.Set("Origin", "https://evil.example.com")
+	request.Header.Set("Access-Control-Request-Method", "POST")
+
+	res := serve(request)
+
+	assert.Equal(t, "", res.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", res.Header.Get("Vary"))
+}
+
+func TestVaryWithoutOrigin(t *testing.T) {
+	res := serve(httptest.NewRequest("POST", "/products", strings.NewReader(`{"name":"a"}`)))
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "", res.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", res.Header.Get("Vary"))
+}
+
+func TestCorsAndSecurityHeaders(t *testing.T) {
+	request := httptest.NewRequest("POST", "/products", strings.NewReader(`{"name":"a"}`))
+	request.Header.Set("Origin", "https://app.example.com")
+
+	res := serve(request)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "https://app.example.com", res.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Request-ID", res.Header.Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, "DENY", res.Header.Get("X-Frame-Options"))
+	assert.Equal(t, "default-src 'none'", res.Header.Get("Content-Security-Policy"))
+	assert.Equal(t, "max-age=3600; includeSubDomains", res.Header.Get("Strict-Transport-Security"))
+}
+
+func TestWildcardOrigin(t *testing.T) {
+	origin, ok := allowedOrigin(&helpers.CorsConfig{AllowedOrigins: []string{"*"}}, "https://a.example.com")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "*", origin)
+
+	origin, _ = allowedOrigin(&helpers.CorsConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, "https://a.example.com")
+	assert.Equal(t, "https://a.example.com", origin)
+}
+
+func TestBodyLimitWithContentLength(t *testing.T) {
+	request := httptest.NewRequest("POST", "/products", strings.NewReader(`{"name":"far too long a name"}`))
+
+	res := serve(request)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+	assert.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+}
+
+func TestBodyLimitWhileReading(t *testing.T) {
+	// A reader of unknown length gets past the Content-Length check and is
+	// only cut off by ReadFromRequestBody.
+	request := httptest.NewRequest("POST", "/products", io.MultiReader(strings.NewReader(`{"name":"far too long a name"}`)))
+	request.ContentLength = -1
+
+	res := serve(request)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+	assert.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+}
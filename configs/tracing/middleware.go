@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"task-one/helpers"
+)
+
+// Middleware opens a server span for every request, continuing the trace of
+// an incoming traceparent header when there is one. Spans started from the
+// request context further down become its children.
+func Middleware(router *httprouter.Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+		route := helpers.RoutePattern(router, request)
+
+		ctx, span := Start(ctx, request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(request.URL.Path),
+			),
+		)
+		defer span.End()
+
+		recorder := helpers.NewResponseRecorder(writer)
+		next.ServeHTTP(recorder, request.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.Status))
+		if recorder.Status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.Status))
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"github.com/go-playground/assert/v2"
+	"github.com/julienschmidt/httprouter"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddlewareContinuesIncomingTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	router := httprouter.New()
+	router.GET("/products/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		_, span := Start(request.Context(), "ProductService.FindById")
+		span.End()
+		writer.WriteHeader(500)
+	})
+
+	request := httptest.NewRequest("GET", "/products/7", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	Middleware(router, router).ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+	assert.Equal(t, 2, len(spans))
+	child, server := spans[0], spans[1]
+	assert.Equal(t, "GET /products/:id", server.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+	assert.Equal(t, "Error", server.Status().Code.String())
+}
@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"task-one/helpers"
+)
+
+// sqlTx is what the SQL repositories need from their helpers.Tx, which is a
+// *database.Tx started by database.SQLTxManager.
+type sqlTx interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	DBSystem() string
+}
+
+// QueryContext runs tx.QueryContext inside a client span carrying the
+// statement. The span covers the round trip, not reading the rows.
+func QueryContext(ctx context.Context, tx helpers.Tx, query string, args ...interface{}) (*sql.Rows, error) {
+	sqlTx := asSQL(tx)
+	ctx, span := startQuery(ctx, sqlTx, query)
+	rows, err := sqlTx.QueryContext(ctx, query, args...)
+	End(span, err)
+	return rows, err
+}
+
+// QueryRowContext is QueryContext for a single row. Errors only surface on
+// Scan, so the span cannot record them.
+func QueryRowContext(ctx context.Context, tx helpers.Tx, query string, args ...interface{}) *sql.Row {
+	sqlTx := asSQL(tx)
+	ctx, span := startQuery(ctx, sqlTx, query)
+	defer span.End()
+	return sqlTx.QueryRowContext(ctx, query, args...)
+}
+
+func ExecContext(ctx context.Context, tx helpers.Tx, query string, args ...interface{}) (sql.Result, error) {
+	sqlTx := asSQL(tx)
+	ctx, span := startQuery(ctx, sqlTx, query)
+	result, err := sqlTx.ExecContext(ctx, query, args...)
+	End(span, err)
+	return result, err
+}
+
+// asSQL panics when a repository of the SQL backend is handed the transaction
+// of another backend, which is a wiring mistake rather than a runtime error.
+func asSQL(tx helpers.Tx) sqlTx {
+	sqlTx, ok := tx.(sqlTx)
+	if !ok {
+		panic(fmt.Sprintf("tracing: %T is not a database/sql transaction", tx))
+	}
+	return sqlTx
+}
+
+func startQuery(ctx context.Context, tx sqlTx, query string) (context.Context, trace.Span) {
+	statement := strings.Join(strings.Fields(query), " ")
+	operation := strings.ToUpper(strings.SplitN(statement, " ", 2)[0])
+
+	return Start(ctx, "db "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemKey.String(tx.DBSystem()),
+			semconv.DBOperation(operation),
+			semconv.DBStatement(statement),
+		),
+	)
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"task-one/helpers"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+const instrumentationName = "task-one"
+
+// Init installs the global tracer provider and the W3C trace context
+// propagator. Spans go to the exporter picked by config: none, stdout, a file
+// of JSON spans, or an OTLP/HTTP collector. The returned function flushes
+// pending spans and must be called on shutdown.
+func Init(config *helpers.TracingConfig) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, closeExporter, err := newExporter(config)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(config.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeErr := closeExporter(); err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
+
+func newExporter(config *helpers.TracingConfig) (sdktrace.SpanExporter, func() error, error) {
+	noClose := func() error { return nil }
+
+	switch config.Exporter {
+	case "", ExporterNone:
+		return nil, noClose, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		return exporter, noClose, err
+	case ExporterFile:
+		file, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		return exporter, file.Close, err
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if config.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(config.Endpoint))
+		}
+		exporter, err := otlptracehttp.New(context.Background(), options...)
+		return exporter, noClose, err
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter %q, expected none, stdout, file or otlp", config.Exporter)
+	}
+}
+
+// Start opens a child span of the span in ctx, if any.
+func Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, options...)
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,13 @@
+package exception
+
+type BadRequestError struct {
+	Message string
+}
+
+func NewBadRequestError(message string) BadRequestError {
+	return BadRequestError{Message: message}
+}
+
+func (exception BadRequestError) Error() string {
+	return exception.Message
+}
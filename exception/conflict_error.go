@@ -0,0 +1,13 @@
+package exception
+
+type ConflictError struct {
+	Message string
+}
+
+func NewConflictError(message string) ConflictError {
+	return ConflictError{Message: message}
+}
+
+func (exception ConflictError) Error() string {
+	return exception.Message
+}
@@ -0,0 +1,59 @@
+package exception
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+	"net"
+	"strings"
+)
+
+// FromDatabaseError translates driver errors the client should know about
+// into the matching exception, and returns any other error unchanged.
+func FromDatabaseError(err error) error {
+	var pqError *pq.Error
+	if errors.As(err, &pqError) {
+		switch {
+		case pqError.Code == "23505":
+			return NewConflictError(duplicateDetail(pqError))
+		case pqError.Code == "23503":
+			return NewConflictError("the record is still referenced by or references another record")
+		case pqError.Code.Class() == "08", pqError.Code.Class() == "57":
+			return NewUnavailableError("the database is unavailable")
+		}
+		return err
+	}
+
+	// The driver reports extended result codes, whose low byte is the
+	// primary code.
+	var sqliteError *sqlite.Error
+	if errors.As(err, &sqliteError) {
+		code := sqliteError.Code()
+		switch {
+		case code == sqlite3.SQLITE_CONSTRAINT_UNIQUE, code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return NewConflictError("a record with the same value already exists")
+		case code == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return NewConflictError("the record is still referenced by or references another record")
+		case code&0xff == sqlite3.SQLITE_BUSY, code&0xff == sqlite3.SQLITE_LOCKED, code&0xff == sqlite3.SQLITE_CANTOPEN:
+			return NewUnavailableError("the database is unavailable")
+		}
+		return err
+	}
+
+	var netError net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, &netError) {
+		return NewUnavailableError("the database is unavailable")
+	}
+
+	return err
+}
+
+func duplicateDetail(pqError *pq.Error) string {
+	if strings.HasPrefix(pqError.Detail, "Key ") {
+		return pqError.Detail
+	}
+	return "a record with the same value already exists"
+}
@@ -1,45 +1,89 @@
 package exception
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
 	"net/http"
-	"task-one/helpers"
+	"runtime/debug"
 )
 
+// ErrorHandler is installed as the router PanicHandler. It renders the known
+// exceptions with their status code and answers anything else with a 500
+// after logging it.
 func ErrorHandler(writer http.ResponseWriter, request *http.Request, err interface{}) {
-
-	if notFoundError(writer, request, err) {
-		return
+	problem, ok := toProblem(err)
+	if !ok {
+		slog.ErrorContext(request.Context(), "unexpected error",
+			slog.String("method", request.Method),
+			slog.String("path", request.URL.Path),
+			slog.String("error", fmt.Sprint(err)),
+			slog.String("stack", string(debug.Stack())),
+		)
 	}
 
-	internalServerError(writer, request, err)
+	if problem.Status == http.StatusUnauthorized {
+		writer.Header().Set("WWW-Authenticate", `Bearer, ApiKey`)
+	}
 
+	writeProblem(writer, request, problem)
 }
 
-func notFoundError(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
-	exception, ok := err.(NotFoundError)
-	if ok {
-		writer.Header().Set("Content-Type", "application/json")
+func toProblem(recovered interface{}) (Problem, bool) {
+	err, isError := recovered.(error)
+	if !isError {
+		return newProblem(http.StatusInternalServerError, "internal", "an unexpected error occurred"), false
+	}
+
+	err = FromDatabaseError(err)
 
-		apiResponse := helpers.ApiResponse{
-			StatusCode: http.StatusNotFound,
-			Data:       exception.Error,
-		}
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		err = NewValidationError(validationErrors)
+	}
 
-		helpers.WriteToResponse(writer, apiResponse, http.StatusNotFound)
-		return true
-	} else {
-		return false
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &syntaxError) || errors.As(err, &typeError) {
+		err = NewBadRequestError(fmt.Sprintf("malformed JSON body: %s", err.Error()))
 	}
-}
 
-func internalServerError(writer http.ResponseWriter, request *http.Request, err interface{}) {
-	writer.Header().Set("Content-Type", "application/json")
+	var badRequest BadRequestError
+	var validation ValidationError
+	var unauthorized UnauthorizedError
+	var forbidden ForbiddenError
+	var notFound NotFoundError
+	var conflict ConflictError
+	var unavailable UnavailableError
+	var payloadTooLarge PayloadTooLargeError
+	var tooManyRequests TooManyRequestsError
 
-	apiResponse := helpers.ApiResponse{
-		StatusCode: http.StatusInternalServerError,
-		Data:       nil,
+	switch {
+	case errors.As(err, &badRequest):
+		return newProblem(http.StatusBadRequest, "bad-request", badRequest.Message), true
+	case errors.As(err, &validation):
+		problem := newProblem(http.StatusUnprocessableEntity, "validation", validation.Error())
+		problem.Errors = validation.Errors
+		return problem, true
+	case errors.As(err, &unauthorized):
+		return newProblem(http.StatusUnauthorized, "unauthorized", unauthorized.Message), true
+	case errors.As(err, &forbidden):
+		return newProblem(http.StatusForbidden, "forbidden", forbidden.Message), true
+	case errors.As(err, &notFound):
+		return newProblem(http.StatusNotFound, "not-found", notFound.Message), true
+	case errors.As(err, &conflict):
+		return newProblem(http.StatusConflict, "conflict", conflict.Message), true
+	case errors.As(err, &payloadTooLarge):
+		return newProblem(http.StatusRequestEntityTooLarge, "payload-too-large", payloadTooLarge.Message), true
+	case errors.As(err, &tooManyRequests):
+		return newProblem(http.StatusTooManyRequests, "too-many-requests", tooManyRequests.Message), true
+	case errors.As(err, &unavailable):
+		return newProblem(http.StatusServiceUnavailable, "unavailable", unavailable.Message), true
 	}
 
-	panic(err)
-	helpers.WriteToResponse(writer, apiResponse, http.StatusInternalServerError)
+	// Anything else, InternalError included, gets a generic 500 and its
+	// cause is logged by ErrorHandler.
+	return newProblem(http.StatusInternalServerError, "internal", "an unexpected error occurred"), false
 }
@@ -0,0 +1,94 @@
+package exception
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/go-playground/assert/v2"
+	"github.com/lib/pq"
+	_ "modernc.org/sqlite"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func handle(err interface{}, requestId string) (*http.Response, Problem) {
+	req := httptest.NewRequest("GET", "http://localhost:3001/categories/1", nil)
+	if requestId != "" {
+		req.Header.Set("X-Request-ID", requestId)
+	}
+	recorder := httptest.NewRecorder()
+
+	ErrorHandler(recorder, req, err)
+
+	res := recorder.Result()
+	var problem Problem
+	json.NewDecoder(res.Body).Decode(&problem)
+	return res, problem
+}
+
+// sqliteError runs statements on a fresh SQLite database and returns the
+// error of the last one.
+func sqliteError(t *testing.T, statements ...string) error {
+	db, err := sql.Open("sqlite", ":memory:?_pragma=foreign_keys(1)")
+	assert.Equal(t, nil, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	for _, statement := range statements {
+		_, err = db.Exec(statement)
+	}
+	return err
+}
+
+func TestErrorHandlerStatusCodes(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    interface{}
+		status int
+	}{
+		{"bad request", NewBadRequestError("id must be a number"), 400},
+		{"unauthorized", NewUnauthorizedError("missing token"), 401},
+		{"forbidden", NewForbiddenError("missing permission"), 403},
+		{"not found", NewNotFoundError("category Not Found"), 404},
+		{"conflict", NewConflictError("duplicate"), 409},
+		{"payload too large", NewPayloadTooLargeError("request body exceeds 1048576 bytes"), 413},
+		{"too many requests", NewTooManyRequestsError("rate limit exceeded"), 429},
+		{"validation", ValidationError{Errors: []FieldError{{Field: "name", Message: "is required"}}}, 422},
+		{"unavailable", NewUnavailableError("database down"), 503},
+		{"internal", NewInternalError(errors.New("boom")), 500},
+		{"unique violation", &pq.Error{Code: "23505"}, 409},
+		{"sqlite unique violation", sqliteError(t, "CREATE TABLE a (id INTEGER PRIMARY KEY)", "INSERT INTO a VALUES (1)", "INSERT INTO a VALUES (1)"), 409},
+		{"sqlite foreign key violation", sqliteError(t, "CREATE TABLE a (id INTEGER PRIMARY KEY)", "CREATE TABLE b (a_id INTEGER REFERENCES a (id))", "INSERT INTO b VALUES (1)"), 409},
+		{"connection refused", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, 503},
+		{"unexpected panic", "something went wrong", 500},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res, problem := handle(c.err, "")
+
+			assert.Equal(t, c.status, res.StatusCode)
+			assert.Equal(t, c.status, problem.Status)
+			assert.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+			assert.Equal(t, http.StatusText(c.status), problem.Title)
+		})
+	}
+}
+
+func TestErrorHandlerProblemBody(t *testing.T) {
+	_, problem := handle(NewNotFoundError("category Not Found"), "req-123")
+
+	assert.Equal(t, "/problems/not-found", problem.Type)
+	assert.Equal(t, "category Not Found", problem.Detail)
+	assert.Equal(t, "/categories/1", problem.Instance)
+	assert.Equal(t, "req-123", problem.RequestId)
+}
+
+func TestErrorHandlerHidesInternalDetail(t *testing.T) {
+	_, problem := handle(errors.New("pq: password authentication failed"), "")
+
+	assert.Equal(t, "an unexpected error occurred", problem.Detail)
+	assert.NotEqual(t, "", problem.RequestId)
+}
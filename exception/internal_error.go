@@ -0,0 +1,22 @@
+package exception
+
+// InternalError wraps a failure the client cannot do anything about. The
+// cause is logged but never sent in the response.
+type InternalError struct {
+	Cause error
+}
+
+func NewInternalError(cause error) InternalError {
+	return InternalError{Cause: cause}
+}
+
+func (exception InternalError) Error() string {
+	if exception.Cause == nil {
+		return "internal server error"
+	}
+	return exception.Cause.Error()
+}
+
+func (exception InternalError) Unwrap() error {
+	return exception.Cause
+}
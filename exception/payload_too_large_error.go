@@ -0,0 +1,13 @@
+package exception
+
+type PayloadTooLargeError struct {
+	Message string
+}
+
+func NewPayloadTooLargeError(message string) PayloadTooLargeError {
+	return PayloadTooLargeError{Message: message}
+}
+
+func (exception PayloadTooLargeError) Error() string {
+	return exception.Message
+}
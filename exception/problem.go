@@ -0,0 +1,54 @@
+package exception
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+)
+
+// Problem is the RFC 7807 body every error response is rendered as.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestId string       `json:"request_id"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+func newProblem(status int, problemType string, detail string) Problem {
+	return Problem{
+		Type:   "/problems/" + problemType,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func writeProblem(writer http.ResponseWriter, request *http.Request, problem Problem) {
+	problem.Instance = request.URL.Path
+	problem.RequestId = requestId(writer, request)
+
+	writer.Header().Set("Content-Type", "application/problem+json")
+	writer.WriteHeader(problem.Status)
+	json.NewEncoder(writer).Encode(problem)
+}
+
+// requestId reuses the X-Request-ID sent by the client or already set on the
+// response, and generates one otherwise so the error can be traced in logs.
+func requestId(writer http.ResponseWriter, request *http.Request) string {
+	id := writer.Header().Get("X-Request-ID")
+	if id == "" {
+		id = request.Header.Get("X-Request-ID")
+	}
+	if id == "" {
+		bytes := make([]byte, 16)
+		rand.Read(bytes)
+		id = hex.EncodeToString(bytes)
+	}
+
+	writer.Header().Set("X-Request-ID", id)
+	return id
+}
@@ -0,0 +1,13 @@
+package exception
+
+type TooManyRequestsError struct {
+	Message string
+}
+
+func NewTooManyRequestsError(message string) TooManyRequestsError {
+	return TooManyRequestsError{Message: message}
+}
+
+func (exception TooManyRequestsError) Error() string {
+	return exception.Message
+}
@@ -0,0 +1,13 @@
+package exception
+
+type UnavailableError struct {
+	Message string
+}
+
+func NewUnavailableError(message string) UnavailableError {
+	return UnavailableError{Message: message}
+}
+
+func (exception UnavailableError) Error() string {
+	return exception.Message
+}
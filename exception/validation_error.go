@@ -0,0 +1,68 @@
+package exception
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strings"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (exception ValidationError) Error() string {
+	return "request validation failed"
+}
+
+func NewValidationError(errors validator.ValidationErrors) ValidationError {
+	var fieldErrors []FieldError
+	for _, fieldError := range errors {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   fieldName(fieldError),
+			Message: fieldMessage(fieldError),
+		})
+	}
+	return ValidationError{Errors: fieldErrors}
+}
+
+// fieldName drops the struct name from the namespace, turning
+// "CreateApiKeyDto.scopes[0]" into "scopes[0]".
+func fieldName(fieldError validator.FieldError) string {
+	namespace := fieldError.Namespace()
+	if index := strings.Index(namespace, "."); index >= 0 {
+		return namespace[index+1:]
+	}
+	return namespace
+}
+
+func fieldMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(fieldError.Param()), ", ")
+	case "min":
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fieldError.Param())
+		}
+		if fieldError.Kind() == reflect.Slice || fieldError.Kind() == reflect.Map {
+			return fmt.Sprintf("must contain at least %s items", fieldError.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fieldError.Param())
+	case "max":
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fieldError.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fieldError.Param())
+	default:
+		return fmt.Sprintf("failed on the %s rule", fieldError.Tag())
+	}
+}
@@ -0,0 +1,44 @@
+package health
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"task-one/helpers"
+)
+
+type HealthController interface {
+	Liveness(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Readiness(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
+
+type HealthControllerImpl struct {
+	Service HealthService
+}
+
+func NewHealthController(service HealthService) HealthController {
+	return &HealthControllerImpl{Service: service}
+}
+
+// Liveness only tells that the process serves requests; it never touches a
+// dependency, so a database outage does not get the process restarted.
+func (controller *HealthControllerImpl) Liveness(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	result := helpers.ApiResponse{
+		StatusCode: 200,
+		Data:       Report{Status: StatusUp},
+	}
+	helpers.WriteToResponse(writer, result, 200)
+}
+
+func (controller *HealthControllerImpl) Readiness(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	report := controller.Service.Ready(request.Context())
+
+	status := 200
+	if report.Status == StatusDown {
+		status = 503
+	}
+	result := helpers.ApiResponse{
+		StatusCode: status,
+		Data:       report,
+	}
+	helpers.WriteToResponse(writer, result, status)
+}
@@ -0,0 +1,82 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-playground/assert/v2"
+	"github.com/julienschmidt/httprouter"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func up(ctx context.Context) error {
+	return nil
+}
+
+func down(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+func readiness(checks ...Check) (*http.Response, map[string]interface{}) {
+	router := httprouter.New()
+	RegisterRoute(router, checks...)
+
+	req := httptest.NewRequest("GET", "http://localhost:3001/readyz", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+	return res, responseBody["data"].(map[string]interface{})
+}
+
+func TestLiveness(t *testing.T) {
+	router := httprouter.New()
+	RegisterRoute(router, Check{Name: "postgres", Required: true, Probe: down})
+
+	req := httptest.NewRequest("GET", "http://localhost:3001/healthz", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, 200, recorder.Result().StatusCode)
+}
+
+func TestReadiness(t *testing.T) {
+	t.Run("Test Readiness All Up", func(t *testing.T) {
+		res, data := readiness(
+			Check{Name: "postgres", Required: true, Probe: up},
+			Check{Name: "redis", Required: true, Probe: up},
+		)
+
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Equal(t, StatusUp, data["status"])
+		assert.Equal(t, StatusUp, data["checks"].(map[string]interface{})["redis"].(map[string]interface{})["status"])
+	})
+
+	t.Run("Test Readiness Optional Dependency Down", func(t *testing.T) {
+		res, data := readiness(
+			Check{Name: "postgres", Required: true, Probe: up},
+			Check{Name: "redis", Required: false, Probe: down},
+		)
+		redis := data["checks"].(map[string]interface{})["redis"].(map[string]interface{})
+
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Equal(t, StatusDown, redis["status"])
+		assert.Equal(t, "connection refused", redis["error"])
+	})
+
+	t.Run("Test Readiness Required Dependency Down", func(t *testing.T) {
+		res, data := readiness(
+			Check{Name: "postgres", Required: true, Probe: down},
+			Check{Name: "redis", Required: true, Probe: up},
+		)
+
+		assert.Equal(t, 503, res.StatusCode)
+		assert.Equal(t, StatusDown, data["status"])
+	})
+}
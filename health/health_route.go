@@ -0,0 +1,16 @@
+package health
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"time"
+)
+
+const checkTimeout = 2 * time.Second
+
+func RegisterRoute(router *httprouter.Router, checks ...Check) {
+	healthService := NewHealthService(checkTimeout, checks...)
+	healthController := NewHealthController(healthService)
+
+	router.GET("/healthz", healthController.Liveness)
+	router.GET("/readyz", healthController.Readiness)
+}
@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check probes one dependency. A failing Required check makes the service
+// unready; other checks are only reported.
+type Check struct {
+	Name     string
+	Required bool
+	Probe    func(ctx context.Context) error
+}
+
+type CheckResult struct {
+	Status    string  `json:"status"`
+	Required  bool    `json:"required"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type HealthService interface {
+	Ready(ctx context.Context) Report
+}
+
+type HealthServiceImpl struct {
+	Checks  []Check
+	Timeout time.Duration
+}
+
+func NewHealthService(timeout time.Duration, checks ...Check) HealthService {
+	return &HealthServiceImpl{Checks: checks, Timeout: timeout}
+}
+
+// Ready runs every check concurrently, each bounded by the service timeout,
+// and reports down if any required check fails. It waits for all of them, so
+// the report gives the state of every dependency.
+func (service *HealthServiceImpl) Ready(ctx context.Context) Report {
+	report := Report{Status: StatusUp, Checks: map[string]CheckResult{}}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, check := range service.Checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			result := service.run(ctx, check)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			report.Checks[check.Name] = result
+			if result.Status == StatusDown && check.Required {
+				report.Status = StatusDown
+			}
+		}(check)
+	}
+
+	wg.Wait()
+	return report
+}
+
+func (service *HealthServiceImpl) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, service.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Probe(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		Required:  check.Required,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
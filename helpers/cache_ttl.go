@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strings"
+	"time"
+)
+
+// CacheTTL is how long a cached entry is kept (TTL) and, within that, how long
+// it is Fresh. A stale entry is still served while a single reader refreshes
+// it, so only that reader waits for the database.
+type CacheTTL struct {
+	Fresh time.Duration
+	TTL   time.Duration
+}
+
+// ParseCacheTTL reads a TTL written as "<fresh>/<ttl>", e.g. "1m/10m", or as a
+// single duration, which keeps nothing stale.
+func ParseCacheTTL(value string) (CacheTTL, error) {
+	fresh, ttl, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		ttl = fresh
+	}
+
+	freshDuration, err := time.ParseDuration(fresh)
+	if err != nil || freshDuration <= 0 {
+		return CacheTTL{}, fmt.Errorf("cache ttl %q has an invalid fresh duration", value)
+	}
+	ttlDuration, err := time.ParseDuration(ttl)
+	if err != nil || ttlDuration < freshDuration {
+		return CacheTTL{}, fmt.Errorf("cache ttl %q must not expire before it goes stale", value)
+	}
+	return CacheTTL{Fresh: freshDuration, TTL: ttlDuration}, nil
+}
+
+// UnmarshalYAML reads a TTL from a config file, where it is written the same
+// way as for ParseCacheTTL.
+func (ttl *CacheTTL) UnmarshalYAML(node *yaml.Node) (err error) {
+	var value string
+	err = node.Decode(&value)
+	if err != nil {
+		return err
+	}
+	*ttl, err = ParseCacheTTL(value)
+	return err
+}
+
+// ParseKeyCacheTTLs reads per cache TTLs written as "<cache>=<ttl>;...", e.g.
+// "list:products=1m/10m;product=30m".
+func ParseKeyCacheTTLs(value string) (map[string]CacheTTL, error) {
+	ttls := map[string]CacheTTL{}
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, raw, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("cache ttl %q is not written as <cache>=<ttl>", entry)
+		}
+		ttl, err := ParseCacheTTL(raw)
+		if err != nil {
+			return nil, err
+		}
+		ttls[strings.TrimSpace(name)] = ttl
+	}
+	return ttls, nil
+}
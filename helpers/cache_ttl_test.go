@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"testing"
+	"time"
+)
+
+func TestParseCacheTTL(t *testing.T) {
+	ttl, err := ParseCacheTTL("1m/10m")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, CacheTTL{Fresh: time.Minute, TTL: 10 * time.Minute}, ttl)
+
+	ttl, err = ParseCacheTTL("30s")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, CacheTTL{Fresh: 30 * time.Second, TTL: 30 * time.Second}, ttl)
+
+	for _, value := range []string{"", "soon", "0s", "10m/1m", "1m/later"} {
+		_, err := ParseCacheTTL(value)
+		assert.NotEqual(t, nil, err)
+	}
+}
+
+func TestParseKeyCacheTTLs(t *testing.T) {
+	ttls, err := ParseKeyCacheTTLs("list:products=1m/10m; product=30m;")
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]CacheTTL{
+		"list:products": {Fresh: time.Minute, TTL: 10 * time.Minute},
+		"product":       {Fresh: 30 * time.Minute, TTL: 30 * time.Minute},
+	}, ttls)
+
+	_, err = ParseKeyCacheTTLs("product")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestCacheConfigTTLFor(t *testing.T) {
+	config := DefaultConfig().Cache
+	config.Keys["product"] = CacheTTL{Fresh: time.Hour, TTL: time.Hour}
+
+	assert.Equal(t, time.Hour, config.TTLFor("product").TTL)
+	assert.Equal(t, config.TTL, config.TTLFor("list:products"))
+}
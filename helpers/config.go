@@ -0,0 +1,504 @@
+package helpers
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type DBConfig struct {
+	Connection  string `yaml:"driver"`
+	URI         string `yaml:"uri"`
+	Test_URI    string `yaml:"test_uri"`
+	AutoMigrate bool   `yaml:"auto_migrate"`
+}
+
+type RedisConfig struct {
+	Host     string `yaml:"host"`
+	Password string `yaml:"password"`
+	Db       int    `yaml:"db"`
+	Optional bool   `yaml:"optional"`
+}
+
+type AppConfig struct {
+	Port            string        `yaml:"port"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	LogLevel        string        `yaml:"log_level"`
+}
+
+type MailConfig struct {
+	SmtpHost     string `yaml:"smtp_host"`
+	SmtpPort     int    `yaml:"smtp_port"`
+	SenderName   string `yaml:"sender_name"`
+	AuthEmail    string `yaml:"auth_email"`
+	AuthPassword string `yaml:"auth_password"`
+	HealthCheck  bool   `yaml:"health_check"`
+}
+
+type JWTConfig struct {
+	Secret          string        `yaml:"secret"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+}
+
+type TracingConfig struct {
+	Exporter    string `yaml:"exporter"`
+	FilePath    string `yaml:"file"`
+	Endpoint    string `yaml:"otlp_endpoint"`
+	ServiceName string `yaml:"service_name"`
+}
+
+// RateLimitConfig holds the Default limit applied to every rate limited
+// route and the per route overrides, keyed by "<METHOD> <path>".
+type RateLimitConfig struct {
+	Enabled bool                 `yaml:"enabled"`
+	Default RateLimit            `yaml:"default"`
+	Routes  map[string]RateLimit `yaml:"routes"`
+}
+
+// CorsConfig lists what browsers on other origins may do. An origin of "*"
+// allows every origin.
+type CorsConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins"`
+	AllowedMethods   []string      `yaml:"allowed_methods"`
+	AllowedHeaders   []string      `yaml:"allowed_headers"`
+	ExposedHeaders   []string      `yaml:"exposed_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+// SecurityConfig holds the security response headers and the request body
+// limit. A zero HSTSMaxAge leaves Strict-Transport-Security unset.
+type SecurityConfig struct {
+	HSTSMaxAge            time.Duration `yaml:"hsts_max_age"`
+	ContentSecurityPolicy string        `yaml:"content_security_policy"`
+	MaxBodyBytes          int64         `yaml:"max_body_bytes"`
+}
+
+// CacheConfig picks where cached reads live: "redis", an in-process "lru"
+// holding up to LRUSize entries, or "none". TTL applies to every cache not
+// listed in Keys, which are named like "list:products" or "product".
+type CacheConfig struct {
+	Driver  string              `yaml:"driver"`
+	LRUSize int                 `yaml:"lru_size"`
+	TTL     CacheTTL            `yaml:"ttl"`
+	Keys    map[string]CacheTTL `yaml:"keys"`
+}
+
+// TTLFor returns the TTL of the cache called name.
+func (config *CacheConfig) TTLFor(name string) CacheTTL {
+	ttl, ok := config.Keys[name]
+	if !ok {
+		return config.TTL
+	}
+	return ttl
+}
+
+type Config struct {
+	DB        *DBConfig        `yaml:"db"`
+	AppConfig *AppConfig       `yaml:"app"`
+	Redis     *RedisConfig     `yaml:"redis"`
+	Mail      *MailConfig      `yaml:"mail"`
+	JWT       *JWTConfig       `yaml:"jwt"`
+	Tracing   *TracingConfig   `yaml:"tracing"`
+	RateLimit *RateLimitConfig `yaml:"rate_limit"`
+	Cors      *CorsConfig      `yaml:"cors"`
+	Security  *SecurityConfig  `yaml:"security"`
+	Cache     *CacheConfig     `yaml:"cache"`
+}
+
+// DefaultConfig is the configuration before any file, environment variable
+// or flag is applied.
+func DefaultConfig() *Config {
+	return &Config{
+		DB: &DBConfig{
+			Connection: "postgres",
+		},
+		AppConfig: &AppConfig{
+			Port:            "localhost:3001",
+			ShutdownTimeout: 30 * time.Second,
+			LogLevel:        "info",
+		},
+		Redis: &RedisConfig{
+			Host: "localhost:6379",
+		},
+		Mail: &MailConfig{
+			SmtpPort: 587,
+		},
+		JWT: &JWTConfig{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+		Tracing: &TracingConfig{
+			Exporter:    "none",
+			FilePath:    "traces.json",
+			ServiceName: "task-one",
+		},
+		RateLimit: &RateLimitConfig{
+			Default: RateLimit{Requests: 100, Window: time.Minute},
+			Routes:  map[string]RateLimit{},
+		},
+		Cors: &CorsConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+			AllowedHeaders: []string{"Authorization", "Content-Type", "X-Request-ID"},
+			ExposedHeaders: []string{"X-Request-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"},
+			MaxAge:         10 * time.Minute,
+		},
+		Security: &SecurityConfig{
+			ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+			MaxBodyBytes:          1 << 20,
+		},
+		Cache: &CacheConfig{
+			Driver:  "redis",
+			LRUSize: 10000,
+			TTL:     CacheTTL{Fresh: 5 * time.Minute, TTL: 10 * time.Minute},
+			Keys:    map[string]CacheTTL{},
+		},
+	}
+}
+
+// LoadConfig builds the configuration from, in increasing precedence, the
+// defaults, the YAML or JSON file named by -config or CONFIG_FILE, the
+// environment and the command line flags in args. Variables from the nearest
+// .env file in the working directory or above are added to the environment
+// first, without overriding variables that are already set; ENV_FILE names
+// another file instead. The arguments left after the flags are returned.
+func LoadConfig(args []string) (*Config, []string, error) {
+	return LoadConfigFrom(DefaultConfig(), args)
+}
+
+// LoadConfigFrom is LoadConfig starting from config instead of the defaults,
+// so tests can default to backends that need nothing installed.
+func LoadConfigFrom(config *Config, args []string) (*Config, []string, error) {
+	err := loadDotEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	flags, apply := configFlags(config)
+	err = flags.Parse(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file := os.Getenv("CONFIG_FILE")
+	if path := flags.Lookup("config").Value.String(); path != "" {
+		file = path
+	}
+	if file != "" {
+		err = config.loadFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = config.loadEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = apply()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return nil, nil, err
+	}
+	return config, flags.Args(), nil
+}
+
+// Validate reports every missing or out of range setting at once.
+func (config *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(oneOf(config.DB.Connection, "postgres", "sqlite", "memory"),
+		"db.driver (DB_DRIVER) must be postgres, sqlite or memory, got %q", config.DB.Connection)
+	check(config.DB.URI != "" || config.DB.Connection == "memory", "db.uri (DB_URI) is required")
+	check(config.AppConfig.Port != "", "app.port (PORT) is required")
+	check(config.AppConfig.ShutdownTimeout > 0, "app.shutdown_timeout (SHUTDOWN_TIMEOUT) must be positive")
+	check(oneOf(strings.ToLower(config.AppConfig.LogLevel), "debug", "info", "warn", "error"),
+		"app.log_level (LOG_LEVEL) must be debug, info, warn or error, got %q", config.AppConfig.LogLevel)
+	check(config.Redis.Host != "", "redis.host (REDIS_HOST) is required")
+	check(config.Redis.Db >= 0 && config.Redis.Db <= 15, "redis.db (REDIS_DB) must be between 0 and 15, got %d", config.Redis.Db)
+	check(config.Mail.SmtpPort > 0 && config.Mail.SmtpPort <= 65535,
+		"mail.smtp_port (CONFIG_SMTP_PORT) must be between 1 and 65535, got %d", config.Mail.SmtpPort)
+	check(config.JWT.Secret != "", "jwt.secret (JWT_SECRET) is required")
+	check(config.JWT.AccessTokenTTL > 0, "jwt.access_token_ttl (JWT_ACCESS_TOKEN_TTL) must be positive")
+	check(config.JWT.RefreshTokenTTL > 0, "jwt.refresh_token_ttl (JWT_REFRESH_TOKEN_TTL) must be positive")
+	check(oneOf(config.Tracing.Exporter, "none", "stdout", "file", "otlp"),
+		"tracing.exporter (TRACING_EXPORTER) must be none, stdout, file or otlp, got %q", config.Tracing.Exporter)
+	check(config.Tracing.Exporter != "otlp" || config.Tracing.Endpoint != "",
+		"tracing.otlp_endpoint (TRACING_OTLP_ENDPOINT) is required for the otlp exporter")
+	check(config.RateLimit.Default.Requests > 0, "rate_limit.default (RATE_LIMIT_DEFAULT) must allow at least one request")
+	for route, limit := range config.RateLimit.Routes {
+		check(limit.Requests > 0, "rate_limit.routes (RATE_LIMIT_ROUTES) must allow at least one request for %q", route)
+	}
+	check(config.Security.MaxBodyBytes > 0, "security.max_body_bytes (MAX_BODY_BYTES) must be positive")
+	check(oneOf(config.Cache.Driver, "redis", "lru", "none"),
+		"cache.driver (CACHE_DRIVER) must be redis, lru or none, got %q", config.Cache.Driver)
+	check(config.Cache.Driver != "lru" || config.Cache.LRUSize > 0, "cache.lru_size (CACHE_LRU_SIZE) must be positive")
+	check(config.Cache.TTL.Fresh > 0 && config.Cache.TTL.TTL >= config.Cache.TTL.Fresh,
+		"cache.ttl (CACHE_TTL) must be positive and not expire before it goes stale")
+	for name, ttl := range config.Cache.Keys {
+		check(oneOf(name, "list:products", "product", "list:categories", "category"),
+			"cache.keys (CACHE_KEY_TTLS) must name list:products, product, list:categories or category, got %q", name)
+		check(ttl.Fresh > 0 && ttl.TTL >= ttl.Fresh,
+			"cache.keys (CACHE_KEY_TTLS) must be positive and not expire before going stale for %q", name)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// loadFile reads a YAML file over config. JSON is read the same way, since it
+// is a subset of YAML.
+func (config *Config) loadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	err = yaml.Unmarshal(content, config)
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (config *Config) loadEnv() error {
+	env := envReader{}
+
+	env.string("DB_DRIVER", &config.DB.Connection)
+	env.string("DB_URI", &config.DB.URI)
+	env.string("DB_TEST_URI", &config.DB.Test_URI)
+	env.bool("DB_AUTO_MIGRATE", &config.DB.AutoMigrate)
+
+	env.string("PORT", &config.AppConfig.Port)
+	env.duration("SHUTDOWN_TIMEOUT", &config.AppConfig.ShutdownTimeout)
+	env.string("LOG_LEVEL", &config.AppConfig.LogLevel)
+
+	env.string("REDIS_HOST", &config.Redis.Host)
+	env.string("REDIS_PASSWORD", &config.Redis.Password)
+	env.int("REDIS_DB", &config.Redis.Db)
+	env.bool("REDIS_OPTIONAL", &config.Redis.Optional)
+
+	env.string("CONFIG_SMTP_HOST", &config.Mail.SmtpHost)
+	env.int("CONFIG_SMTP_PORT", &config.Mail.SmtpPort)
+	env.string("CONFIG_SENDER_NAME", &config.Mail.SenderName)
+	env.string("CONFIG_AUTH_EMAIL", &config.Mail.AuthEmail)
+	env.string("CONFIG_AUTH_PASSWORD", &config.Mail.AuthPassword)
+	env.bool("CONFIG_SMTP_HEALTHCHECK", &config.Mail.HealthCheck)
+
+	env.string("JWT_SECRET", &config.JWT.Secret)
+	env.duration("JWT_ACCESS_TOKEN_TTL", &config.JWT.AccessTokenTTL)
+	env.duration("JWT_REFRESH_TOKEN_TTL", &config.JWT.RefreshTokenTTL)
+
+	env.string("TRACING_EXPORTER", &config.Tracing.Exporter)
+	env.string("TRACING_FILE", &config.Tracing.FilePath)
+	env.string("TRACING_OTLP_ENDPOINT", &config.Tracing.Endpoint)
+	env.string("TRACING_SERVICE_NAME", &config.Tracing.ServiceName)
+
+	env.bool("RATE_LIMIT_ENABLED", &config.RateLimit.Enabled)
+	env.parse("RATE_LIMIT_DEFAULT", func(value string) (err error) {
+		config.RateLimit.Default, err = ParseRateLimit(value)
+		return err
+	})
+	env.parse("RATE_LIMIT_ROUTES", func(value string) (err error) {
+		config.RateLimit.Routes, err = ParseRouteRateLimits(value)
+		return err
+	})
+
+	env.list("CORS_ALLOWED_ORIGINS", &config.Cors.AllowedOrigins)
+	env.list("CORS_ALLOWED_METHODS", &config.Cors.AllowedMethods)
+	env.list("CORS_ALLOWED_HEADERS", &config.Cors.AllowedHeaders)
+	env.list("CORS_EXPOSED_HEADERS", &config.Cors.ExposedHeaders)
+	env.bool("CORS_ALLOW_CREDENTIALS", &config.Cors.AllowCredentials)
+	env.duration("CORS_MAX_AGE", &config.Cors.MaxAge)
+
+	env.duration("SECURITY_HSTS_MAX_AGE", &config.Security.HSTSMaxAge)
+	env.string("SECURITY_CSP", &config.Security.ContentSecurityPolicy)
+	env.parse("MAX_BODY_BYTES", func(value string) (err error) {
+		config.Security.MaxBodyBytes, err = strconv.ParseInt(value, 10, 64)
+		return err
+	})
+
+	env.string("CACHE_DRIVER", &config.Cache.Driver)
+	env.int("CACHE_LRU_SIZE", &config.Cache.LRUSize)
+	env.parse("CACHE_TTL", func(value string) (err error) {
+		config.Cache.TTL, err = ParseCacheTTL(value)
+		return err
+	})
+	env.parse("CACHE_KEY_TTLS", func(value string) (err error) {
+		config.Cache.Keys, err = ParseKeyCacheTTLs(value)
+		return err
+	})
+
+	return errors.Join(env.errs...)
+}
+
+// configFlags defines the command line flags. Their values are only written
+// to config when the returned apply is called, so that they can take
+// precedence over the file and the environment read in between.
+func configFlags(config *Config) (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("task-one", flag.ContinueOnError)
+	flags.String("config", "", "YAML or JSON config `file`")
+
+	var setters []func() error
+	set := func(name string, usage string, parse func(value string) error) {
+		flags.Func(name, usage, func(value string) error {
+			setters = append(setters, func() error {
+				err := parse(value)
+				if err != nil {
+					return fmt.Errorf("flag -%s: %w", name, err)
+				}
+				return nil
+			})
+			return nil
+		})
+	}
+	str := func(target *string) func(string) error {
+		return func(value string) error {
+			*target = value
+			return nil
+		}
+	}
+
+	set("port", "listen `address`", str(&config.AppConfig.Port))
+	set("log-level", "debug, info, warn or error", str(&config.AppConfig.LogLevel))
+	set("db-driver", "database driver", str(&config.DB.Connection))
+	set("db-uri", "database `uri`", str(&config.DB.URI))
+	set("db-auto-migrate", "apply pending migrations on startup", func(value string) (err error) {
+		config.DB.AutoMigrate, err = strconv.ParseBool(value)
+		return err
+	})
+	set("redis-host", "redis `address`", str(&config.Redis.Host))
+	set("redis-db", "redis database `number`", func(value string) (err error) {
+		config.Redis.Db, err = strconv.Atoi(value)
+		return err
+	})
+	set("cache", "redis, lru or none", str(&config.Cache.Driver))
+	set("tracing-exporter", "none, stdout, file or otlp", str(&config.Tracing.Exporter))
+	set("rate-limit", "enable rate limiting", func(value string) (err error) {
+		config.RateLimit.Enabled, err = strconv.ParseBool(value)
+		return err
+	})
+
+	return flags, func() error {
+		for _, setter := range setters {
+			err := setter()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// loadDotEnv adds the variables of ENV_FILE, or else of the nearest .env file
+// in the working directory or one of its parents, to the environment. It is
+// not an error when there is no .env file.
+func loadDotEnv() error {
+	if path := os.Getenv("ENV_FILE"); path != "" {
+		return godotenv.Load(path)
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	for {
+		path := filepath.Join(dir, ".env")
+		if _, err := os.Stat(path); err == nil {
+			return godotenv.Load(path)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// envReader writes the environment variables that are set to a non-empty
+// value over their config fields, collecting the ones that cannot be parsed.
+type envReader struct {
+	errs []error
+}
+
+func (env *envReader) parse(name string, parse func(value string) error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	if err := parse(value); err != nil {
+		env.errs = append(env.errs, fmt.Errorf("%s=%q: %w", name, value, err))
+	}
+}
+
+func (env *envReader) string(name string, target *string) {
+	env.parse(name, func(value string) error {
+		*target = value
+		return nil
+	})
+}
+
+func (env *envReader) int(name string, target *int) {
+	env.parse(name, func(value string) (err error) {
+		*target, err = strconv.Atoi(value)
+		return err
+	})
+}
+
+func (env *envReader) bool(name string, target *bool) {
+	env.parse(name, func(value string) (err error) {
+		*target, err = strconv.ParseBool(value)
+		return err
+	})
+}
+
+func (env *envReader) duration(name string, target *time.Duration) {
+	env.parse(name, func(value string) (err error) {
+		*target, err = time.ParseDuration(value)
+		return err
+	})
+}
+
+// list splits a comma separated value, dropping blank entries.
+func (env *envReader) list(name string, target *[]string) {
+	env.parse(name, func(value string) error {
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*target = list
+		return nil
+	})
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// isolate points ENV_FILE at an empty file and blanks the variables the tests
+// rely on, so neither a developer's .env nor their shell leaks into the test,
+// and sets the required variables.
+func isolate(t *testing.T) string {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	os.WriteFile(envFile, nil, 0o600)
+	t.Setenv("ENV_FILE", envFile)
+	for _, name := range []string{"CONFIG_FILE", "DB_DRIVER", "PORT", "REDIS_HOST", "REDIS_DB", "CONFIG_SMTP_PORT", "SHUTDOWN_TIMEOUT", "LOG_LEVEL", "CACHE_DRIVER"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("DB_URI", "postgres://localhost/task_one")
+	t.Setenv("JWT_SECRET", "secret")
+	return dir
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	isolate(t)
+
+	config, args, err := LoadConfig([]string{"migrate", "status"})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"migrate", "status"}, args)
+	assert.Equal(t, "postgres", config.DB.Connection)
+	assert.Equal(t, "localhost:3001", config.AppConfig.Port)
+	assert.Equal(t, 30*time.Second, config.AppConfig.ShutdownTimeout)
+	assert.Equal(t, 587, config.Mail.SmtpPort)
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	dir := isolate(t)
+	file := filepath.Join(dir, "config.yaml")
+	os.WriteFile(file, []byte(`
+app:
+  port: ":4000"
+  log_level: debug
+redis:
+  host: redis-from-file:6379
+  db: 2
+rate_limit:
+  default: 50/1m
+  routes:
+    POST /auth/login: 5/1m
+`), 0o600)
+	t.Setenv("REDIS_HOST", "redis-from-env:6379")
+	t.Setenv("PORT", ":5000")
+
+	config, _, err := LoadConfig([]string{"-config", file, "-port", ":6000"})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ":6000", config.AppConfig.Port)
+	assert.Equal(t, "redis-from-env:6379", config.Redis.Host)
+	assert.Equal(t, 2, config.Redis.Db)
+	assert.Equal(t, "debug", config.AppConfig.LogLevel)
+	assert.Equal(t, RateLimit{Requests: 50, Window: time.Minute}, config.RateLimit.Default)
+	assert.Equal(t, RateLimit{Requests: 5, Window: time.Minute}, config.RateLimit.Routes["POST /auth/login"])
+	assert.Equal(t, 15*time.Minute, config.JWT.AccessTokenTTL)
+}
+
+func TestLoadConfigJSONFile(t *testing.T) {
+	dir := isolate(t)
+	file := filepath.Join(dir, "config.json")
+	os.WriteFile(file, []byte(`{"app": {"shutdown_timeout": "5s"}, "cors": {"allowed_origins": ["https://app.example.com"]}}`), 0o600)
+	t.Setenv("CONFIG_FILE", file)
+
+	config, _, err := LoadConfig(nil)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 5*time.Second, config.AppConfig.ShutdownTimeout)
+	assert.Equal(t, []string{"https://app.example.com"}, config.Cors.AllowedOrigins)
+}
+
+func TestLoadConfigRejectsBadNumbers(t *testing.T) {
+	isolate(t)
+	t.Setenv("REDIS_DB", "one")
+	t.Setenv("CONFIG_SMTP_PORT", "smtp")
+
+	_, _, err := LoadConfig(nil)
+
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, true, strings.Contains(err.Error(), `REDIS_DB="one"`))
+	assert.Equal(t, true, strings.Contains(err.Error(), `CONFIG_SMTP_PORT="smtp"`))
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	isolate(t)
+	t.Setenv("DB_URI", "")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("REDIS_DB", "99")
+	t.Setenv("CACHE_KEY_TTLS", "products=1m")
+
+	_, _, err := LoadConfig(nil)
+
+	assert.NotEqual(t, nil, err)
+	for _, field := range []string{"db.uri (DB_URI) is required", "jwt.secret (JWT_SECRET) is required", "redis.db (REDIS_DB)", `got "products"`} {
+		assert.Equal(t, true, strings.Contains(err.Error(), field))
+	}
+}
@@ -0,0 +1,75 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"task-one/exception"
+)
+
+// Cursor marks the last row of a keyset page: the value of the sort field and
+// the id breaking ties on it. Clients only ever see it encoded, as an opaque
+// string.
+type Cursor struct {
+	Field string `json:"f"`
+	Value string `json:"v"`
+	Id    int    `json:"id"`
+}
+
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(encoded string) (Cursor, error) {
+	cursor := Cursor{}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, exception.NewBadRequestError("cursor is invalid")
+	}
+
+	err = json.Unmarshal(data, &cursor)
+	if err != nil {
+		return cursor, exception.NewBadRequestError("cursor is invalid")
+	}
+	return cursor, nil
+}
+
+// KeysetCondition renders the predicate selecting the rows after the cursor,
+// as `id > $n` when sorting by id only and as a row comparison such as
+// `(name, id) > ($n, $n+1)` otherwise, so Postgres can seek straight into the
+// matching (column, id) index. argCount is the number of arguments already
+// bound in the query.
+func (query ListQuery) KeysetCondition(columns map[string]string, argCount int) (string, []interface{}) {
+	operator := " > "
+	if query.Sort[0].Desc {
+		operator = " < "
+	}
+
+	idColumn := columns["id"]
+	if len(query.Sort) == 1 {
+		return idColumn + operator + "$" + strconv.Itoa(argCount+1), []interface{}{query.Cursor.Id}
+	}
+
+	column := columns[query.Sort[0].Field]
+	condition := "(" + column + ", " + idColumn + ")" + operator +
+		"($" + strconv.Itoa(argCount+1) + ", $" + strconv.Itoa(argCount+2) + ")"
+	var value interface{} = query.Cursor.Value
+	if isIdField(query.Sort[0].Field) {
+		// parseCursor has checked that the value is a number.
+		value, _ = strconv.Atoi(query.Cursor.Value)
+	}
+	return condition, []interface{}{value, query.Cursor.Id}
+}
+
+// isIdField tells whether a sort field holds ids, which every list names
+// "id" or "<table>_id", rather than text.
+func isIdField(field string) bool {
+	return field == "id" || strings.HasSuffix(field, "_id")
+}
+
+// NextCursor is the cursor pointing after id, whose sort field has value.
+func (query ListQuery) NextCursor(value string, id int) string {
+	return EncodeCursor(Cursor{Field: query.Sort[0].Field, Value: value, Id: id})
+}
@@ -0,0 +1,67 @@
+package helpers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strings"
+)
+
+// ResponseRecorder wraps a ResponseWriter to remember the status code and the
+// number of body bytes written, for middleware reporting on the response.
+type ResponseRecorder struct {
+	http.ResponseWriter
+	Status int
+	Bytes  int
+}
+
+func NewResponseRecorder(writer http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{ResponseWriter: writer, Status: http.StatusOK}
+}
+
+func (recorder *ResponseRecorder) WriteHeader(status int) {
+	recorder.Status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *ResponseRecorder) Write(data []byte) (int, error) {
+	n, err := recorder.ResponseWriter.Write(data)
+	recorder.Bytes += n
+	return n, err
+}
+
+// routeProbe stands in for one path segment while RoutePattern looks for the
+// parameters; no real path contains it.
+const routeProbe = "\x00"
+
+// RoutePattern returns the pattern of the route matching request, e.g.
+// /products/:id. httprouter only reports parameter values, so each segment is
+// swapped for routeProbe in turn: the segment is a parameter when the router
+// binds the probe to it. Requests matching no route give "unmatched".
+func RoutePattern(router *httprouter.Router, request *http.Request) string {
+	handle, params, _ := router.Lookup(request.Method, request.URL.Path)
+	if handle == nil {
+		return "unmatched"
+	}
+	if len(params) == 0 {
+		return request.URL.Path
+	}
+
+	segments := strings.Split(request.URL.Path, "/")
+	pattern := append([]string(nil), segments...)
+	for i := range segments {
+		original := segments[i]
+		segments[i] = routeProbe
+		_, probed, _ := router.Lookup(request.Method, strings.Join(segments, "/"))
+		segments[i] = original
+
+		for _, param := range probed {
+			if param.Value == routeProbe {
+				pattern[i] = ":" + param.Key
+			} else if strings.HasPrefix(param.Value, "/"+routeProbe) {
+				// A catch-all parameter takes the rest of the path.
+				return strings.Join(append(pattern[:i], "*"+param.Key), "/")
+			}
+		}
+	}
+	return strings.Join(pattern, "/")
+}
@@ -2,13 +2,39 @@ package helpers
 
 import (
 	"encoding/json"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"io"
 	"net/http"
+	"strconv"
+	"task-one/exception"
 )
 
-func ReadFromRequestBody(request *http.Request, result interface{}) {
+// ReadFromRequestBody decodes the JSON body into result and runs the
+// `validate` tags of result against it. A body that is not valid JSON is
+// reported as exception.BadRequestError, a failed validation as
+// exception.ValidationError and a body cut off by http.MaxBytesReader as
+// exception.PayloadTooLargeError.
+func ReadFromRequestBody(request *http.Request, result interface{}) error {
 	decoder := json.NewDecoder(request.Body)
 	err := decoder.Decode(result)
-	PanicIfError(err)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return exception.NewPayloadTooLargeError("request body exceeds " + strconv.FormatInt(tooLarge.Limit, 10) + " bytes")
+	}
+	if errors.Is(err, io.EOF) {
+		return exception.NewBadRequestError("request body is empty")
+	}
+	if err != nil {
+		return exception.NewBadRequestError("malformed JSON body: " + err.Error())
+	}
+
+	err = Validate(result)
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return exception.NewValidationError(validationErrors)
+	}
+	return err
 }
 
 func WriteToResponse(writer http.ResponseWriter, response interface{}, statusCode int) {
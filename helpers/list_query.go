@@ -0,0 +1,212 @@
+package helpers
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"task-one/exception"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListQuery holds the page, sort order and filters of a list endpoint, parsed
+// from query parameters such as `?page=2&limit=10&sort=name,-id&name~=tab`.
+//
+// Passing a `cursor` parameter, empty for the first page, switches to keyset
+// pagination: Page is ignored and Cursor holds the position to continue from.
+type ListQuery struct {
+	Page       int
+	Limit      int
+	Sort       []SortField
+	Filters    map[string]string
+	CursorMode bool
+	Cursor     *Cursor
+}
+
+// PageInfo is what a repository reports about the page it returned besides
+// its rows. Total is only counted for offset pagination, NextCursor is only
+// set for keyset pagination when more rows follow.
+type PageInfo struct {
+	Total      int
+	NextCursor string
+}
+
+// ParseListQuery reads page, limit, sort, cursor and the allowed filters from
+// values. Only fields listed in sortable may be sorted on, and only keys
+// listed in filters are kept. The result is always sorted by id last, so pages
+// are stable when the requested sort has ties.
+func ParseListQuery(values url.Values, sortable []string, filters []string) (ListQuery, error) {
+	query := ListQuery{Page: 1, Limit: DefaultPageLimit, Filters: map[string]string{}}
+
+	if page := values.Get("page"); page != "" {
+		number, err := strconv.Atoi(page)
+		if err != nil || number < 1 {
+			return query, exception.NewBadRequestError("page must be a positive number")
+		}
+		query.Page = number
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		number, err := strconv.Atoi(limit)
+		if err != nil || number < 1 || number > MaxPageLimit {
+			return query, exception.NewBadRequestError("limit must be between 1 and " + strconv.Itoa(MaxPageLimit))
+		}
+		query.Limit = number
+	}
+
+	sortedById := false
+	for _, field := range strings.Split(values.Get("sort"), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		sortField := SortField{Field: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+		if !contains(sortable, sortField.Field) {
+			return query, exception.NewBadRequestError("cannot sort by " + sortField.Field)
+		}
+		if sortField.Field == "id" {
+			sortedById = true
+		}
+		query.Sort = append(query.Sort, sortField)
+	}
+	if !sortedById {
+		query.Sort = append(query.Sort, SortField{Field: "id"})
+	}
+
+	if encoded, ok := values["cursor"]; ok {
+		err := query.parseCursor(encoded[0])
+		if err != nil {
+			return query, err
+		}
+	}
+
+	for _, filter := range filters {
+		if value := values.Get(filter); value != "" {
+			query.Filters[filter] = value
+		}
+	}
+
+	return query, nil
+}
+
+// parseCursor checks that the sort order can be walked with a keyset, which
+// means a single field followed by id, both in the same direction, and that
+// the cursor was issued for that same field.
+func (query *ListQuery) parseCursor(encoded string) error {
+	query.CursorMode = true
+	query.Page = 1
+
+	if len(query.Sort) > 2 || query.Sort[len(query.Sort)-1].Field != "id" {
+		return exception.NewBadRequestError("cursor pagination supports sorting by a single field")
+	}
+	// The implicit id tiebreaker follows the direction of the sort field.
+	query.Sort[len(query.Sort)-1].Desc = query.Sort[0].Desc
+
+	if encoded == "" {
+		return nil
+	}
+
+	cursor, err := DecodeCursor(encoded)
+	if err != nil {
+		return err
+	}
+	if cursor.Field != query.Sort[0].Field {
+		return exception.NewBadRequestError("cursor does not match the sort order")
+	}
+	if len(query.Sort) > 1 && isIdField(cursor.Field) {
+		if _, err := strconv.Atoi(cursor.Value); err != nil {
+			return exception.NewBadRequestError("cursor is invalid")
+		}
+	}
+	query.Cursor = &cursor
+	return nil
+}
+
+func (query ListQuery) Offset() int {
+	return (query.Page - 1) * query.Limit
+}
+
+// CacheKey renders the query in a canonical form, so two requests asking for
+// the same page share a cache entry regardless of parameter order.
+func (query ListQuery) CacheKey() string {
+	var sortFields []string
+	for _, field := range query.Sort {
+		if field.Desc {
+			sortFields = append(sortFields, "-"+field.Field)
+		} else {
+			sortFields = append(sortFields, field.Field)
+		}
+	}
+
+	var filterKeys []string
+	for key := range query.Filters {
+		filterKeys = append(filterKeys, key)
+	}
+	sort.Strings(filterKeys)
+
+	key := "page=" + strconv.Itoa(query.Page) + "&limit=" + strconv.Itoa(query.Limit) + "&sort=" + strings.Join(sortFields, ",")
+	if query.CursorMode {
+		key = "cursor=" + query.cursorKey() + "&limit=" + strconv.Itoa(query.Limit) + "&sort=" + strings.Join(sortFields, ",")
+	}
+	for _, filterKey := range filterKeys {
+		key += "&" + filterKey + "=" + url.QueryEscape(query.Filters[filterKey])
+	}
+	return key
+}
+
+func (query ListQuery) cursorKey() string {
+	if query.Cursor == nil {
+		return ""
+	}
+	return EncodeCursor(*query.Cursor)
+}
+
+// OrderBy renders the sort fields as an ORDER BY clause, mapping each field to
+// its column. Fields have already been checked against the sortable list, so
+// only known column names ever reach the SQL.
+func (query ListQuery) OrderBy(columns map[string]string) string {
+	var clauses []string
+	for _, field := range query.Sort {
+		clause := columns[field.Field]
+		if field.Desc {
+			clause += " DESC"
+		}
+		clauses = append(clauses, clause)
+	}
+	return " ORDER BY " + strings.Join(clauses, ", ")
+}
+
+// WhereClause joins conditions with AND, or returns an empty string when
+// there are none.
+func WhereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// ContainsPattern turns value into a LIKE pattern matching it anywhere,
+// escaping the LIKE wildcards it contains.
+func ContainsPattern(value string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return "%" + escaper.Replace(value) + "%"
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestParseListQuery(t *testing.T) {
+	values, _ := url.ParseQuery("page=2&limit=5&sort=name,-category_id&name~=tab&password=x")
+
+	query, err := ParseListQuery(values, []string{"id", "name", "category_id"}, []string{"name~"})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, query.Page)
+	assert.Equal(t, 5, query.Limit)
+	assert.Equal(t, 5, query.Offset())
+	assert.Equal(t, []SortField{{Field: "name"}, {Field: "category_id", Desc: true}, {Field: "id"}}, query.Sort)
+	assert.Equal(t, map[string]string{"name~": "tab"}, query.Filters)
+	assert.Equal(t, " ORDER BY p.name, p.category_id DESC, p.id", query.OrderBy(map[string]string{
+		"id": "p.id", "name": "p.name", "category_id": "p.category_id",
+	}))
+}
+
+func TestParseListQueryRejectsInvalidInput(t *testing.T) {
+	for _, raw := range []string{"page=0", "limit=1000", "limit=abc", "sort=password"} {
+		values, _ := url.ParseQuery(raw)
+		_, err := ParseListQuery(values, []string{"id", "name"}, nil)
+
+		assert.NotEqual(t, nil, err)
+	}
+}
+
+func TestListQueryCacheKeyIsCanonical(t *testing.T) {
+	first, _ := url.ParseQuery("name~=tab&category_id=1&limit=5")
+	second, _ := url.ParseQuery("limit=5&category_id=1&name~=tab")
+	filters := []string{"category_id", "name~"}
+
+	firstQuery, _ := ParseListQuery(first, []string{"id"}, filters)
+	secondQuery, _ := ParseListQuery(second, []string{"id"}, filters)
+
+	assert.Equal(t, firstQuery.CacheKey(), secondQuery.CacheKey())
+}
+
+func TestNewMeta(t *testing.T) {
+	request := httptest.NewRequest("GET", "http://localhost:3001/products?page=2&limit=10", nil)
+	query, _ := ParseListQuery(request.URL.Query(), []string{"id"}, nil)
+
+	meta := NewMeta(request, query, PageInfo{Total: 25})
+
+	assert.Equal(t, "/products?limit=10&page=3", meta.Next)
+	assert.Equal(t, "/products?limit=10&page=1", meta.Prev)
+	assert.Equal(t, "", NewMeta(request, query, PageInfo{Total: 20}).Next)
+}
+
+func TestParseListQueryWithCursor(t *testing.T) {
+	cursor := EncodeCursor(Cursor{Field: "name", Value: "Meja", Id: 7})
+	values := url.Values{"sort": {"-name"}, "cursor": {cursor}, "limit": {"2"}}
+
+	query, err := ParseListQuery(values, []string{"id", "name"}, nil)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, query.CursorMode)
+	assert.Equal(t, []SortField{{Field: "name", Desc: true}, {Field: "id", Desc: true}}, query.Sort)
+	assert.Equal(t, Cursor{Field: "name", Value: "Meja", Id: 7}, *query.Cursor)
+
+	condition, args := query.KeysetCondition(map[string]string{"id": "p.id", "name": "p.name"}, 1)
+	assert.Equal(t, "(p.name, p.id) < ($2, $3)", condition)
+	assert.Equal(t, []interface{}{"Meja", 7}, args)
+}
+
+func TestParseListQueryRejectsInvalidCursor(t *testing.T) {
+	nameCursor := EncodeCursor(Cursor{Field: "name", Value: "Meja", Id: 7})
+	for _, values := range []url.Values{
+		{"cursor": {"not-a-cursor"}},
+		{"cursor": {nameCursor}},
+		{"cursor": {""}, "sort": {"-id,name"}},
+		{"cursor": {EncodeCursor(Cursor{Field: "category_id", Value: "abc", Id: 7})}, "sort": {"category_id"}},
+	} {
+		_, err := ParseListQuery(values, []string{"id", "name", "category_id"}, nil)
+
+		assert.NotEqual(t, nil, err)
+	}
+}
+
+func TestKeysetConditionBindsIdsAsNumbers(t *testing.T) {
+	cursor := EncodeCursor(Cursor{Field: "category_id", Value: "3", Id: 7})
+	query, err := ParseListQuery(url.Values{"sort": {"category_id"}, "cursor": {cursor}}, []string{"id", "category_id"}, nil)
+	assert.Equal(t, nil, err)
+
+	_, args := query.KeysetCondition(map[string]string{"id": "p.id", "category_id": "p.category_id"}, 0)
+	assert.Equal(t, []interface{}{3, 7}, args)
+}
+
+func TestNewMetaWithCursor(t *testing.T) {
+	request := httptest.NewRequest("GET", "http://localhost:3001/products?cursor=&limit=10", nil)
+	query, _ := ParseListQuery(request.URL.Query(), []string{"id"}, nil)
+
+	meta := NewMeta(request, query, PageInfo{NextCursor: "abc"})
+
+	assert.Equal(t, "abc", meta.NextCursor)
+	assert.Equal(t, "/products?cursor=abc&limit=10", meta.Next)
+	assert.Equal(t, (*int)(nil), meta.Total)
+}
+
+func TestContainsPattern(t *testing.T) {
+	assert.Equal(t, `%50\%\_off%`, ContainsPattern("50%_off"))
+}
@@ -0,0 +1,17 @@
+package helpers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"strconv"
+	"task-one/exception"
+)
+
+// ParamToInt reads a numeric route parameter such as :id and returns an
+// exception.BadRequestError when it is not a number.
+func ParamToInt(params httprouter.Params, name string) (int, error) {
+	value, err := strconv.Atoi(params.ByName(name))
+	if err != nil {
+		return 0, exception.NewBadRequestError(name + " must be a number")
+	}
+	return value, nil
+}
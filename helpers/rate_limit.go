@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit allows Requests requests per Window.
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// ParseRateLimit reads a limit written as "<requests>/<window>", e.g. "100/1m".
+func ParseRateLimit(value string) (RateLimit, error) {
+	parts := strings.SplitN(strings.TrimSpace(value), "/", 2)
+	if len(parts) != 2 {
+		return RateLimit{}, fmt.Errorf("rate limit %q is not written as <requests>/<window>", value)
+	}
+
+	requests, err := strconv.Atoi(parts[0])
+	if err != nil || requests <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q has an invalid request count", value)
+	}
+	window, err := time.ParseDuration(parts[1])
+	if err != nil || window <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q has an invalid window", value)
+	}
+	return RateLimit{Requests: requests, Window: window}, nil
+}
+
+// UnmarshalYAML reads a limit from a config file, where it is written the
+// same way as for ParseRateLimit.
+func (limit *RateLimit) UnmarshalYAML(node *yaml.Node) (err error) {
+	var value string
+	err = node.Decode(&value)
+	if err != nil {
+		return err
+	}
+	*limit, err = ParseRateLimit(value)
+	return err
+}
+
+// ParseRouteRateLimits reads per route limits written as
+// "<METHOD> <path>=<limit>;...", e.g. "POST /auth/login=5/1m;POST /products=10/1m".
+func ParseRouteRateLimits(value string) (map[string]RateLimit, error) {
+	routes := map[string]RateLimit{}
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, raw, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("route rate limit %q is not written as <route>=<limit>", entry)
+		}
+		limit, err := ParseRateLimit(raw)
+		if err != nil {
+			return nil, err
+		}
+		routes[strings.Join(strings.Fields(route), " ")] = limit
+	}
+	return routes, nil
+}
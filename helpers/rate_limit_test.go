@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	limit, err := ParseRateLimit("100/1m")
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, RateLimit{Requests: 100, Window: time.Minute}, limit)
+
+	for _, value := range []string{"", "100", "abc/1m", "10/forever", "10/0s", "0/1m"} {
+		_, err := ParseRateLimit(value)
+		assert.NotEqual(t, nil, err)
+	}
+}
+
+func TestParseRouteRateLimits(t *testing.T) {
+	routes, err := ParseRouteRateLimits("POST  /auth/login=5/1m; POST /products=10/30s;")
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]RateLimit{
+		"POST /auth/login": {Requests: 5, Window: time.Minute},
+		"POST /products":   {Requests: 10, Window: 30 * time.Second},
+	}, routes)
+
+	_, err = ParseRouteRateLimits("POST /products")
+	assert.NotEqual(t, nil, err)
+}
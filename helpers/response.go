@@ -1,6 +1,58 @@
 package helpers
 
+import (
+	"net/http"
+	"strconv"
+)
+
 type ApiResponse struct {
 	StatusCode int         `json:"statusCode"`
 	Data       interface{} `json:"data"`
+	Meta       *Meta       `json:"meta,omitempty"`
+}
+
+// Meta describes the page of a list response. Offset pages report total,
+// page and prev/next links; keyset pages report next_cursor and a next link
+// instead, since counting every row would defeat their purpose.
+type Meta struct {
+	Total      *int   `json:"total,omitempty"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewMeta builds the meta block for query. The next and prev links repeat the
+// request with only the page or cursor parameter changed.
+func NewMeta(request *http.Request, query ListQuery, pageInfo PageInfo) *Meta {
+	if query.CursorMode {
+		meta := &Meta{Limit: query.Limit, NextCursor: pageInfo.NextCursor}
+		if pageInfo.NextCursor != "" {
+			meta.Next = pageLink(request, "cursor", pageInfo.NextCursor)
+		}
+		return meta
+	}
+
+	total := pageInfo.Total
+	meta := &Meta{
+		Total: &total,
+		Page:  query.Page,
+		Limit: query.Limit,
+	}
+
+	if query.Offset()+query.Limit < total {
+		meta.Next = pageLink(request, "page", strconv.Itoa(query.Page+1))
+	}
+	if query.Page > 1 {
+		meta.Prev = pageLink(request, "page", strconv.Itoa(query.Page-1))
+	}
+
+	return meta
+}
+
+func pageLink(request *http.Request, parameter string, value string) string {
+	values := request.URL.Query()
+	values.Set(parameter, value)
+	return request.URL.Path + "?" + values.Encode()
 }
@@ -1,15 +1,97 @@
 package helpers
 
-import "database/sql"
-
-func CommitOrRollback(tx *sql.Tx) {
-	err := recover()
-	if err != nil {
-		errorRollback := tx.Rollback()
-		PanicIfError(errorRollback)
-		panic(err)
-	} else {
-		errorCommit := tx.Commit()
-		PanicIfError(errorCommit)
+import (
+	"context"
+	"sync"
+)
+
+// Tx is a unit of work: the writes made through it become visible together on
+// Commit or not at all on Rollback. The SQL and in-memory backends each wrap
+// their own transaction to satisfy it.
+type Tx interface {
+	Commit() error
+	Rollback() error
+	// AfterCommit queues hook to run once the transaction has committed. The
+	// hooks of a rolled back transaction never run. Side effects that must
+	// only follow committed data, such as cache writes and mails, go there.
+	AfterCommit(hook func())
+	// AfterRollback queues hook to run once the transaction has rolled back
+	// or failed to commit. Whatever was held for the transaction, such as a
+	// cache lock, is released there when it never commits.
+	AfterRollback(hook func())
+}
+
+// CommitHooks implements Tx.AfterCommit and Tx.AfterRollback for the
+// transactions embedding it, which call Run after a successful commit and
+// RunRollback otherwise.
+type CommitHooks struct {
+	mu            sync.Mutex
+	hooks         []func()
+	rollbackHooks []func()
+}
+
+func (commitHooks *CommitHooks) AfterCommit(hook func()) {
+	commitHooks.mu.Lock()
+	defer commitHooks.mu.Unlock()
+	commitHooks.hooks = append(commitHooks.hooks, hook)
+}
+
+func (commitHooks *CommitHooks) AfterRollback(hook func()) {
+	commitHooks.mu.Lock()
+	defer commitHooks.mu.Unlock()
+	commitHooks.rollbackHooks = append(commitHooks.rollbackHooks, hook)
+}
+
+// Run calls the commit hooks in the order they were queued.
+func (commitHooks *CommitHooks) Run() {
+	hooks, _ := commitHooks.take()
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+// RunRollback calls the rollback hooks in the order they were queued.
+func (commitHooks *CommitHooks) RunRollback() {
+	_, hooks := commitHooks.take()
+	for _, hook := range hooks {
+		hook()
 	}
 }
+
+func (commitHooks *CommitHooks) take() ([]func(), []func()) {
+	commitHooks.mu.Lock()
+	defer commitHooks.mu.Unlock()
+	hooks, rollbackHooks := commitHooks.hooks, commitHooks.rollbackHooks
+	commitHooks.hooks, commitHooks.rollbackHooks = nil, nil
+	return hooks, rollbackHooks
+}
+
+// TxManager starts units of work for one repository backend. Services only
+// depend on TxManager and pass the Tx on to the repositories of the same
+// backend.
+type TxManager interface {
+	Begin(ctx context.Context) (Tx, error)
+}
+
+// CommitOrRollback finishes tx based on the error the calling function is
+// about to return. It is meant to be deferred with a pointer to a named error
+// result:
+//
+//	defer helpers.CommitOrRollback(tx, &err)
+//
+// The transaction is rolled back when *err is set and committed otherwise, in
+// which case a failed commit is reported back through *err. A panic escaping
+// the caller still rolls the transaction back before it propagates.
+func CommitOrRollback(tx Tx, err *error) {
+	if recovered := recover(); recovered != nil {
+		tx.Rollback()
+		panic(recovered)
+	}
+
+	if *err != nil {
+		tx.Rollback()
+		return
+	}
+
+	*err = tx.Commit()
+}
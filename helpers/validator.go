@@ -0,0 +1,27 @@
+package helpers
+
+import (
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strings"
+)
+
+var validate = newValidator()
+
+// newValidator reports fields by their json name, so errors point at the keys
+// clients actually send instead of the Go struct fields.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+func Validate(value interface{}) error {
+	return validate.Struct(value)
+}
@@ -1,26 +1,124 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	_ "github.com/lib/pq"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"task-one/configs/database"
+	"task-one/configs/logger"
 	"task-one/configs/route"
+	"task-one/configs/tracing"
 	"task-one/helpers"
 )
 
 func main() {
-	router := route.NewRouter()
-	env := helpers.GetConfig()
+	env, args, err := helpers.LoadConfig(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	log := logger.New(env.AppConfig.LogLevel)
+	slog.SetDefault(log)
+
+	if len(args) > 0 && args[0] == "migrate" {
+		migrate(env, log, args[1:])
+		return
+	}
+
+	shutdownTracing, err := tracing.Init(env.Tracing)
+	if err != nil {
+		log.Error("failed to set up tracing", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	app := route.NewApp(env, log)
 
 	PORT := env.AppConfig.Port
 
 	server := http.Server{
 		Addr:    PORT,
-		Handler: router,
+		Handler: app.Handler,
 	}
 
-	log.Println("Server Running On : http://" + PORT)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	err := server.ListenAndServe()
-	helpers.PanicIfError(err)
+	go func() {
+		log.Info("server running", slog.String("addr", "http://"+PORT))
+
+		err := server.ListenAndServe()
+		if err != http.ErrServerClosed {
+			log.Error("server stopped", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Info("shutting down", slog.Duration("drain_timeout", env.AppConfig.ShutdownTimeout))
+
+	// One deadline covers both draining requests and flushing pending mail.
+	drainCtx, cancel := context.WithTimeout(context.Background(), env.AppConfig.ShutdownTimeout)
+	defer cancel()
+
+	err = server.Shutdown(drainCtx)
+	if err != nil {
+		log.Error("failed to drain requests", slog.String("error", err.Error()))
+	}
+
+	err = app.Close(drainCtx)
+	if err != nil {
+		log.Error("failed to shut down cleanly", slog.String("error", err.Error()))
+	}
+
+	err = shutdownTracing(drainCtx)
+	if err != nil {
+		log.Error("failed to flush traces", slog.String("error", err.Error()))
+	}
+	log.Info("server stopped")
+}
+
+// migrate runs `migrate up`, `migrate down [steps]` or `migrate status`
+// against the configured database.
+func migrate(env *helpers.Config, log *slog.Logger, args []string) {
+	db, dialect := database.OpenDb(env.DB)
+	defer db.Close()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		err := database.Migrate(db, dialect)
+		helpers.PanicIfError(err)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			number, err := strconv.Atoi(args[1])
+			if err != nil || number < 1 {
+				log.Error("migrate down: steps must be a positive number")
+				os.Exit(2)
+			}
+			steps = number
+		}
+		err := database.MigrateDown(db, dialect, steps)
+		helpers.PanicIfError(err)
+	case "status":
+		statuses, err := database.Status(db, dialect)
+		helpers.PanicIfError(err)
+		for _, status := range statuses {
+			log.Info("migration", slog.Int("version", status.Version), slog.String("name", status.Name), slog.Bool("applied", status.Applied))
+		}
+	default:
+		log.Error("unknown migrate command, expected up, down or status", slog.String("command", command))
+		os.Exit(2)
+	}
 }
@@ -24,3 +24,25 @@ func ToProductResponses(products []Product) []response.ProductResponse {
 	}
 	return productResponses
 }
+
+type ProductSearchResult struct {
+	Product
+	Score                 float64
+	NameHighlight         string
+	CategoryNameHighlight string
+}
+
+func ToProductSearchResponses(results []ProductSearchResult) []response.ProductSearchResponse {
+	var searchResponses []response.ProductSearchResponse
+	for _, result := range results {
+		searchResponses = append(searchResponses, response.ProductSearchResponse{
+			ProductResponse: ToProductResponse(result.Product),
+			Score:           result.Score,
+			Highlights: response.ProductHighlights{
+				Name:         result.NameHighlight,
+				CategoryName: result.CategoryNameHighlight,
+			},
+		})
+	}
+	return searchResponses
+}
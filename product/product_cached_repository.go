@@ -0,0 +1,139 @@
+package product
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"task-one/category"
+	"task-one/configs/cache"
+	"task-one/helpers"
+	"task-one/product/model"
+)
+
+const (
+	productListCachePrefix = "list:products:"
+	productListCacheName   = "list:products"
+	productCachePrefix     = "product:"
+	productCacheName       = "product"
+)
+
+// ProductCachedRepository caches the products of another ProductRepository
+// under product:{id} and its list pages in a cache.Namespace, so that a write
+// only deletes the entry of the product it touches and swaps the list
+// version. Cached products leave out the category name, which is read through
+// Categories instead, so renaming a category never leaves them stale. Reads
+// go through a cache.Loader, and cache writes wait for the transaction to
+// commit.
+type ProductCachedRepository struct {
+	Repository ProductRepository
+	Categories category.CategoryRepository
+	Loader     *cache.Loader
+	Lists      cache.Namespace
+	ListTTL    helpers.CacheTTL
+	TTL        helpers.CacheTTL
+	Logger     *slog.Logger
+}
+
+// productPage is what gets cached for one list query: the rows of the page
+// together with the total or next cursor, so a cache hit can still render the
+// meta block.
+type productPage struct {
+	Products []model.Product  `json:"products"`
+	PageInfo helpers.PageInfo `json:"page_info"`
+}
+
+func NewProductCachedRepository(repository ProductRepository, categories category.CategoryRepository, loader *cache.Loader, config *helpers.CacheConfig, logger *slog.Logger) ProductRepository {
+	return &ProductCachedRepository{
+		Repository: repository,
+		Categories: categories,
+		Loader:     loader,
+		Lists:      cache.NewNamespace(loader.Cache, productListCachePrefix, logger),
+		ListTTL:    config.TTLFor(productListCacheName),
+		TTL:        config.TTLFor(productCacheName),
+		Logger:     logger,
+	}
+}
+
+func (p *ProductCachedRepository) Save(ctx context.Context, tx helpers.Tx, product model.Product) (model.Product, error) {
+	product, err := p.Repository.Save(ctx, tx, product)
+	if err != nil {
+		return product, err
+	}
+	cache.AfterCommit(ctx, tx, p.Logger, p.Lists.Invalidate)
+	return product, nil
+}
+
+func (p *ProductCachedRepository) Update(ctx context.Context, tx helpers.Tx, product model.Product) (model.Product, error) {
+	product, err := p.Repository.Update(ctx, tx, product)
+	if err != nil {
+		return product, err
+	}
+	p.invalidate(ctx, tx, product.Id)
+	return product, nil
+}
+
+func (p *ProductCachedRepository) Delete(ctx context.Context, tx helpers.Tx, productId int) error {
+	err := p.Repository.Delete(ctx, tx, productId)
+	if err != nil {
+		return err
+	}
+	p.invalidate(ctx, tx, productId)
+	return nil
+}
+
+func (p *ProductCachedRepository) FindAll(ctx context.Context, tx helpers.Tx, query helpers.ListQuery) ([]model.Product, helpers.PageInfo, error) {
+	// The key holds the list version read before the query, so a page made
+	// stale by a write committing meanwhile lands in an orphaned version.
+	key, err := p.Lists.Key(ctx, query.CacheKey())
+	if err != nil {
+		return nil, helpers.PageInfo{}, err
+	}
+
+	page, err := cache.Load(ctx, p.Loader, tx, productListCacheName, key, p.ListTTL, func(ctx context.Context, tx helpers.Tx) (page productPage, err error) {
+		p.Logger.DebugContext(ctx, "loading product list", slog.String("key", key))
+		page.Products, page.PageInfo, err = p.Repository.FindAll(ctx, tx, query)
+		return page, err
+	})
+	return page.Products, page.PageInfo, err
+}
+
+func (p *ProductCachedRepository) FindById(ctx context.Context, tx helpers.Tx, productId int) (model.Product, error) {
+	product, err := cache.Load(ctx, p.Loader, tx, productCacheName, productCacheKey(productId), p.TTL, func(ctx context.Context, tx helpers.Tx) (model.Product, error) {
+		product, err := p.Repository.FindById(ctx, tx, productId)
+		product.CategoryName = ""
+		return product, err
+	})
+	if err != nil {
+		return product, err
+	}
+	return p.withCategory(ctx, tx, product)
+}
+
+func (p *ProductCachedRepository) Search(ctx context.Context, tx helpers.Tx, search string, query helpers.ListQuery) ([]model.ProductSearchResult, helpers.PageInfo, error) {
+	return p.Repository.Search(ctx, tx, search, query)
+}
+
+func (p *ProductCachedRepository) withCategory(ctx context.Context, tx helpers.Tx, product model.Product) (model.Product, error) {
+	category, err := p.Categories.FindById(ctx, tx, product.CategoryId)
+	if err != nil {
+		return product, err
+	}
+	product.CategoryName = category.Name
+	return product, nil
+}
+
+// invalidate evicts the entry of productId and every list page once tx
+// commits.
+func (p *ProductCachedRepository) invalidate(ctx context.Context, tx helpers.Tx, productId int) {
+	cache.AfterCommit(ctx, tx, p.Logger, func(ctx context.Context) error {
+		err := p.Loader.Invalidate(ctx, productCacheKey(productId))
+		if err != nil {
+			return err
+		}
+		return p.Lists.Invalidate(ctx)
+	})
+}
+
+func productCacheKey(productId int) string {
+	return productCachePrefix + strconv.Itoa(productId)
+}
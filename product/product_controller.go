@@ -2,8 +2,11 @@ package product
 
 import (
 	"github.com/julienschmidt/httprouter"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"task-one/exception"
 	"task-one/helpers"
 	"task-one/product/dto"
 )
@@ -14,55 +17,82 @@ type ProductController interface {
 	Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 	FindById(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 	FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Search(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 }
 
 type ProductControllerImpl struct {
 	Service ProductService
+	Logger  *slog.Logger
 }
 
-func NewProductController(service ProductService) ProductController {
-	return &ProductControllerImpl{Service: service}
+func NewProductController(service ProductService, logger *slog.Logger) ProductController {
+	return &ProductControllerImpl{Service: service, Logger: logger}
 }
 
 func (controller *ProductControllerImpl) Create(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	productRequest := &dto.ProductCreateDto{}
-	helpers.ReadFromRequestBody(request, productRequest)
+	err := helpers.ReadFromRequestBody(request, productRequest)
+	if err != nil {
+		controller.Logger.DebugContext(request.Context(), "invalid product body", slog.Any("error", err))
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	data := controller.Service.Create(request.Context(), productRequest)
+	data, err := controller.Service.Create(request.Context(), productRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 	result := helpers.ApiResponse{
 		StatusCode: 201,
 		Data:       data,
 	}
 
 	helpers.WriteToResponse(writer, result, 201)
+
 }
 
 func (controller *ProductControllerImpl) Update(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	productUpdateRequest := &dto.ProductUpdateDto{}
-	helpers.ReadFromRequestBody(request, productUpdateRequest)
+	err := helpers.ReadFromRequestBody(request, productUpdateRequest)
+	if err != nil {
+		controller.Logger.DebugContext(request.Context(), "invalid product body", slog.Any("error", err))
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	productId := params.ByName("id")
-	res, err := strconv.Atoi(productId)
-	helpers.PanicIfError(err)
+	productId, err := helpers.ParamToInt(params, "id")
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	productUpdateRequest.Id = res
+	productUpdateRequest.Id = productId
 
-	data := controller.Service.Update(request.Context(), productUpdateRequest)
+	data, err := controller.Service.Update(request.Context(), productUpdateRequest)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 	result := helpers.ApiResponse{
 		StatusCode: 201,
 		Data:       data,
 	}
-
 	helpers.WriteToResponse(writer, result, 201)
-
 }
 
 func (controller *ProductControllerImpl) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	productId := params.ByName("id")
-	res, err := strconv.Atoi(productId)
-	helpers.PanicIfError(err)
+	productId, err := helpers.ParamToInt(params, "id")
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	controller.Service.Delete(request.Context(), res)
+	err = controller.Service.Delete(request.Context(), productId)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 	result := helpers.ApiResponse{
 		StatusCode: 200,
 		Data:       nil,
@@ -72,11 +102,17 @@ func (controller *ProductControllerImpl) Delete(writer http.ResponseWriter, requ
 }
 
 func (controller *ProductControllerImpl) FindById(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	productId := params.ByName("id")
-	res, err := strconv.Atoi(productId)
-	helpers.PanicIfError(err)
+	productId, err := helpers.ParamToInt(params, "id")
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 
-	data := controller.Service.FindById(request.Context(), res)
+	data, err := controller.Service.FindById(request.Context(), productId)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 	result := helpers.ApiResponse{
 		StatusCode: 200,
 		Data:       data,
@@ -86,11 +122,61 @@ func (controller *ProductControllerImpl) FindById(writer http.ResponseWriter, re
 }
 
 func (controller *ProductControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	data := controller.Service.FindAll(request.Context())
+	query, err := helpers.ParseListQuery(request.URL.Query(), ProductSortFields, ProductFilters)
+	if err != nil {
+		controller.Logger.DebugContext(request.Context(), "invalid product list query", slog.Any("error", err))
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	if categoryId, ok := query.Filters["category_id"]; ok {
+		if _, err := strconv.Atoi(categoryId); err != nil {
+			exception.ErrorHandler(writer, request, exception.NewBadRequestError("category_id must be a number"))
+			return
+		}
+	}
+
+	data, pageInfo, err := controller.Service.FindAll(request.Context(), query)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
 	result := helpers.ApiResponse{
 		StatusCode: 200,
 		Data:       data,
+		Meta:       helpers.NewMeta(request, query, pageInfo),
 	}
 	helpers.WriteToResponse(writer, result, 200)
+}
+
+func (controller *ProductControllerImpl) Search(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	values := request.URL.Query()
+	search := strings.TrimSpace(values.Get("q"))
+	if search == "" {
+		exception.ErrorHandler(writer, request, exception.NewBadRequestError("q is required"))
+		return
+	}
+
+	// Results are ordered by relevance, so only page and limit apply.
+	query, err := helpers.ParseListQuery(values, nil, nil)
+	if err != nil {
+		controller.Logger.DebugContext(request.Context(), "invalid product search query", slog.Any("error", err))
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	if query.CursorMode {
+		exception.ErrorHandler(writer, request, exception.NewBadRequestError("search does not support cursor pagination"))
+		return
+	}
 
+	data, pageInfo, err := controller.Service.Search(request.Context(), search, query)
+	if err != nil {
+		exception.ErrorHandler(writer, request, err)
+		return
+	}
+	result := helpers.ApiResponse{
+		StatusCode: 200,
+		Data:       data,
+		Meta:       helpers.NewMeta(request, query, pageInfo),
+	}
+	helpers.WriteToResponse(writer, result, 200)
 }
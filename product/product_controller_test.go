@@ -2,51 +2,103 @@ package product
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"github.com/go-playground/assert/v2"
 	"github.com/julienschmidt/httprouter"
-	_ "github.com/lib/pq"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"strings"
+	"task-one/auth"
+	user_model "task-one/auth/model"
 	"task-one/category"
-	"task-one/category/model"
-	"task-one/configs/database"
-	"task-one/configs/redis"
+	category_model "task-one/category/model"
+	"task-one/configs/cache"
+	"task-one/configs/memory"
+	"task-one/configs/ratelimit"
 	"task-one/exception"
-	product_model "task-one/product/model"
+	"task-one/helpers"
+	"task-one/product/model"
+	"task-one/testutil"
 	"testing"
+	"time"
 )
 
-func setupRouter(db *sql.DB) http.Handler {
+var env = testutil.Config()
+
+var tokens = auth.NewTokenManager(&helpers.JWTConfig{
+	Secret:          "test-secret",
+	AccessTokenTTL:  time.Minute,
+	RefreshTokenTTL: time.Hour,
+})
+
+// testBackend holds the repositories over testutil.Backend, behind the cache
+// kept in a fake Redis. Mails are recorded rather than sent.
+type testBackend struct {
+	testutil.Backend
+	Repository ProductRepository
+	Categories category.CategoryRepository
+	ApiKeys    auth.ApiKeyRepository
+	Cache      *testutil.FakeRedis
+	Mailer     *testutil.RecordingMailer
+}
+
+func newTestBackend() testBackend {
+	backend := testBackend{Backend: testutil.NewBackend(env.DB, "product", "category"), Cache: testutil.NewFakeRedis(), Mailer: testutil.NewRecordingMailer()}
+	if backend.InMemory() {
+		categories := category.NewCategoryMemoryRepository()
+		backend.Repository = NewProductMemoryRepository(categories)
+		backend.Categories = categories
+		backend.ApiKeys = auth.NewApiKeyMemoryRepository()
+	} else {
+		backend.Repository = NewProductRepository(backend.Dialect, slog.Default())
+		backend.Categories = category.NewCategoryRepository(backend.Dialect, slog.Default())
+		backend.ApiKeys = auth.NewApiKeyRepository(backend.Dialect)
+	}
+
+	loader := cache.NewLoader(cache.NewRedisCache(backend.Cache), cache.NewRedisLocker(backend.Cache), backend.TxManager, backend.Serial(), slog.Default())
+	backend.Categories = category.NewCategoryCachedRepository(backend.Categories, loader, env.Cache, slog.Default())
+	backend.Repository = NewProductCachedRepository(backend.Repository, backend.Categories, loader, env.Cache, slog.Default())
+	return backend
+}
+
+func (backend testBackend) fixtures(t *testing.T) *testutil.Fixtures {
+	return &testutil.Fixtures{T: t, TxManager: backend.TxManager, Categories: backend.Categories, Products: backend.Repository}
+}
+
+func setupRouter(backend testBackend) http.Handler {
 	router := httprouter.New()
 	router.PanicHandler = exception.ErrorHandler
-	RegisterRoute(router, db)
+	apiKeyService := auth.NewApiKeyService(backend.ApiKeys, backend.TxManager, slog.Default())
+	RegisterRoute(router, backend.TxManager, backend.Repository, backend.Categories, backend.Mailer, auth.NewAuthMiddleware(tokens, apiKeyService), ratelimit.NewUnlimitedMiddleware(), ratelimit.NewUnlimitedMiddleware(), slog.Default())
 
 	return router
-
 }
 
-func truncateCategory(db *sql.DB) {
-	db.Exec("TRUNCATE category")
-	db.Exec("TRUNCATE product")
+func authorize(request *http.Request) {
+	authorizeAs(request, auth.RoleEditor)
+}
 
+func authorizeAs(request *http.Request, role string) {
+	token, _ := tokens.GenerateAccessToken(user_model.User{Id: 1, Email: "test@mail.com", Role: role})
+	request.Header.Add("Authorization", "Bearer "+token)
 }
 
+// cachedEntries counts the cache entries under prefix, leaving out the
+// versions of their namespaces.
 func TestMain(m *testing.M) {
 	m.Run()
 }
 
 func TestGetListProduct(t *testing.T) {
-	db := database.ConnectToDbTest()
-	router := setupRouter(db)
+	router := setupRouter(newTestBackend())
 
 	req := httptest.NewRequest("GET", "http://localhost:3001/products", nil)
 	req.Header.Add("Content-Type", "application/json")
+	authorize(req)
 
 	recorder := httptest.NewRecorder()
 
@@ -55,22 +107,137 @@ func TestGetListProduct(t *testing.T) {
 	assert.Equal(t, 200, res.StatusCode)
 }
 
-func TestCreateProduct(t *testing.T) {
-	db := database.ConnectToDbTest()
-	router := setupRouter(db)
-	truncateCategory(db)
-	tx, _ := db.Begin()
-
-	categoryRepository := category.NewCategoryRepository()
-	category := categoryRepository.Save(context.Background(), tx, model.Category{
-		Name: "Furniture",
-	})
+func TestGetProductAnonymous(t *testing.T) {
+	backend := newTestBackend()
+	router := setupRouter(backend)
+	product := backend.fixtures(t).Product().Create()
+
+	for _, path := range []string{"/products", "/products/" + strconv.Itoa(product.Id), "/products/search?q=a"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "http://localhost:3001"+path, nil))
+		assert.Equal(t, 200, recorder.Result().StatusCode)
+	}
+}
+
+func TestProductCache(t *testing.T) {
+	backend := newTestBackend()
+	router := setupRouter(backend)
+	fixtures := backend.fixtures(t)
+	category := fixtures.Category().Name("Furniture").Create()
+	product := fixtures.Product().Name("Table").Category(category).Create()
+	path := "http://localhost:3001/products/" + strconv.Itoa(product.Id)
+
+	get := func(url string) interface{} {
+		req := httptest.NewRequest("GET", url, nil)
+		authorize(req)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		var responseBody map[string]interface{}
+		json.NewDecoder(recorder.Result().Body).Decode(&responseBody)
+		return responseBody["data"]
+	}
+
+	get("http://localhost:3001/products")
+	assert.Equal(t, "Table", get(path).(map[string]interface{})["name"])
+	assert.Equal(t, "Furniture", get(path).(map[string]interface{})["category_name"])
+	assert.Equal(t, 1, len(backend.Cache.Keys("product:")))
+	assert.Equal(t, 1, len(backend.Cache.Keys("category:")))
+
+	// The cached product reads its category name through the category entry,
+	// which the rename evicts.
+	ctx := context.Background()
+	tx, _ := backend.TxManager.Begin(ctx)
+	_, err := backend.Categories.Update(ctx, tx, category_model.Category{Id: category.Id, Name: "Mebel"})
+	assert.Equal(t, nil, err)
 	tx.Commit()
+	assert.Equal(t, "Mebel", get(path).(map[string]interface{})["category_name"])
+
+	req := httptest.NewRequest("DELETE", path, nil)
+	authorize(req)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, 200, recorder.Result().StatusCode)
+	assert.Equal(t, nil, get(path))
+	assert.Equal(t, nil, get("http://localhost:3001/products"))
+}
+
+func TestProductCacheWaitsForCommit(t *testing.T) {
+	backend := newTestBackend()
+	router := setupRouter(backend)
+	product := backend.fixtures(t).Product().Name("Table").Create()
+	ctx := context.Background()
+
+	tx, _ := backend.TxManager.Begin(ctx)
+	backend.Repository.FindById(ctx, tx, product.Id)
+	assert.Equal(t, 0, len(backend.Cache.Keys("product:")))
+	tx.Commit()
+	assert.Equal(t, 1, len(backend.Cache.Keys("product:")))
+
+	tx, _ = backend.TxManager.Begin(ctx)
+	backend.Repository.Update(ctx, tx, model.Product{Id: product.Id, Name: "Kursi"})
+	tx.Rollback()
+	assert.Equal(t, 1, len(backend.Cache.Keys("product:")))
+
+	req := httptest.NewRequest("GET", "http://localhost:3001/products/"+strconv.Itoa(product.Id), nil)
+	authorize(req)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	var responseBody map[string]interface{}
+	json.NewDecoder(recorder.Result().Body).Decode(&responseBody)
+	assert.Equal(t, "Table", responseBody["data"].(map[string]interface{})["name"])
+}
+
+func TestProductsWithoutRedis(t *testing.T) {
+	backend := newTestBackend()
+	router := setupRouter(backend)
+	product := backend.fixtures(t).Product().Name("Table").Create()
+	backend.Cache.Err = errors.New("connection refused")
+
+	for _, path := range []string{"/products", "/products/" + strconv.Itoa(product.Id)} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "http://localhost:3001"+path, nil))
+		assert.Equal(t, 200, recorder.Result().StatusCode)
+		assert.Equal(t, true, strings.Contains(recorder.Body.String(), "Table"))
+	}
+}
+
+func TestProductCacheDropsReadsOverlappingAWrite(t *testing.T) {
+	ctx := context.Background()
+	rdb := testutil.NewFakeRedis()
+	loader := cache.NewLoader(cache.NewRedisCache(rdb), cache.NewRedisLocker(rdb), memory.NewStore(), true, slog.Default())
+	stored := category.NewCategoryMemoryRepository()
+	categories := category.NewCategoryCachedRepository(stored, loader, env.Cache, slog.Default())
+	repository := NewProductCachedRepository(NewProductMemoryRepository(stored), categories, loader, env.Cache, slog.Default())
+	fixtures := &testutil.Fixtures{T: t, TxManager: memory.NewStore(), Categories: categories, Products: repository}
+	product := fixtures.Product().Name("Table").Create()
+
+	// Each store only serializes its own transactions, which lets the reader
+	// load the product before the write and commit, caching what it loaded,
+	// after it.
+	reader, _ := memory.NewStore().Begin(ctx)
+	repository.FindById(ctx, reader, product.Id)
+	writer, _ := memory.NewStore().Begin(ctx)
+	repository.Update(ctx, writer, model.Product{Id: product.Id, Name: "Kursi"})
+	writer.Commit()
+	reader.Commit()
+
+	tx, _ := memory.NewStore().Begin(ctx)
+	found, _ := repository.FindById(ctx, tx, product.Id)
+	tx.Commit()
+	assert.Equal(t, "Kursi", found.Name)
+}
+
+func TestCreateProduct(t *testing.T) {
+	backend := newTestBackend()
+	router := setupRouter(backend)
+	category := backend.fixtures(t).Category().Name("Furniture").Create()
 
 	t.Run("Test Create Product Success", func(t *testing.T) {
 		reqBody := strings.NewReader(`{"name" : "Table","category_id":` + strconv.Itoa(category.Id) + "}")
 		req := httptest.NewRequest("POST", "http://localhost:3001/products", reqBody)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
@@ -84,12 +251,34 @@ func TestCreateProduct(t *testing.T) {
 		assert.Equal(t, 201, res.StatusCode)
 		assert.Equal(t, "Table", responseBody["data"].(map[string]interface{})["name"])
 		assert.Equal(t, "Furniture", responseBody["data"].(map[string]interface{})["category_name"])
+		assert.Equal(t, 1, len(backend.Mailer.Mails()))
+		assert.Equal(t, "Terbaru Asli!", backend.Mailer.Mails()[0].Subject)
+	})
+
+	t.Run("Test Create Product Validation Failed", func(t *testing.T) {
+		reqBody := strings.NewReader(`{"name" : "Table"}`)
+		req := httptest.NewRequest("POST", "http://localhost:3001/products", reqBody)
+		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		res := recorder.Result()
+
+		body, _ := ioutil.ReadAll(res.Body)
+		var responseBody map[string]interface{}
+		json.Unmarshal(body, &responseBody)
+
+		assert.Equal(t, 422, res.StatusCode)
+		assert.Equal(t, "category_id", responseBody["errors"].([]interface{})[0].(map[string]interface{})["field"])
 	})
 
 	t.Run("Test Create Product Failed", func(t *testing.T) {
 		reqBody := strings.NewReader(`{"name" : "Table","category_id":404}`)
 		req := httptest.NewRequest("POST", "http://localhost:3001/products", reqBody)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
@@ -104,34 +293,32 @@ func TestCreateProduct(t *testing.T) {
 	})
 }
 
-func TestUpdateProduct(t *testing.T) {
-	db := database.ConnectToDbTest()
-	router := setupRouter(db)
-	truncateCategory(db)
-	tx, _ := db.Begin()
+func TestDeleteProductForbidden(t *testing.T) {
+	router := setupRouter(newTestBackend())
 
-	ctx := context.Background()
+	req := httptest.NewRequest("DELETE", "http://localhost:3001/products/404", nil)
+	req.Header.Add("Content-Type", "application/json")
+	authorizeAs(req, auth.RoleViewer)
 
-	categoryRepository := category.NewCategoryRepository()
-	category := categoryRepository.Save(ctx, tx, model.Category{
-		Name: "Furniture",
-	})
-	categoryUpdate := categoryRepository.Save(ctx, tx, model.Category{
-		Name: "Alat Rumah",
-	})
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
 
-	rdb := redis.InitRedis()
-	productRepository := NewProductRepository(rdb)
-	product := productRepository.Save(ctx, tx, product_model.Product{
-		Name:       "Table",
-		CategoryId: category.Id,
-	})
-	tx.Commit()
+	res := recorder.Result()
+	assert.Equal(t, 403, res.StatusCode)
+}
+
+func TestUpdateProduct(t *testing.T) {
+	backend := newTestBackend()
+	router := setupRouter(backend)
+	fixtures := backend.fixtures(t)
+	categoryUpdate := fixtures.Category().Name("Alat Rumah").Create()
+	product := fixtures.Product().Name("Table").Create()
 
 	t.Run("Test Update Product Success", func(t *testing.T) {
 		reqBody := strings.NewReader(`{"name" : "Meja","category_id":` + strconv.Itoa(categoryUpdate.Id) + "}")
 		req := httptest.NewRequest("PATCH", "http://localhost:3001/products/"+strconv.Itoa(product.Id), reqBody)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
 
@@ -150,6 +337,7 @@ func TestUpdateProduct(t *testing.T) {
 		reqBody := strings.NewReader(`{"name" : "Table","category_id":404}`)
 		req := httptest.NewRequest("PATCH", "http://localhost:3001/products/"+strconv.Itoa(product.Id), reqBody)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
 
@@ -164,30 +352,16 @@ func TestUpdateProduct(t *testing.T) {
 }
 
 func TestGetProductById(t *testing.T) {
-	db := database.ConnectToDbTest()
-	router := setupRouter(db)
-	truncateCategory(db)
-	tx, _ := db.Begin()
-
-	ctx := context.Background()
-
-	categoryRepository := category.NewCategoryRepository()
-	category := categoryRepository.Save(ctx, tx, model.Category{
-		Name: "Furniture",
-	})
-	log.Println(category)
-	rdb := redis.InitRedis()
-	productRepository := NewProductRepository(rdb)
-	log.Println("wow")
-	product := productRepository.Save(ctx, tx, product_model.Product{
-		Name:       "Table",
-		CategoryId: category.Id,
-	})
-	tx.Commit()
+	backend := newTestBackend()
+	router := setupRouter(backend)
+	fixtures := backend.fixtures(t)
+	category := fixtures.Category().Name("Furniture").Create()
+	product := fixtures.Product().Name("Table").Category(category).Create()
 
 	t.Run("Test Get Product By Id Success", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "http://localhost:3001/products/"+strconv.Itoa(product.Id), nil)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
 
@@ -205,6 +379,7 @@ func TestGetProductById(t *testing.T) {
 	t.Run("Test Get Product By Id Failed", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "http://localhost:3001/products/404", nil)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
 
@@ -219,30 +394,14 @@ func TestGetProductById(t *testing.T) {
 }
 
 func TestDeleteProduct(t *testing.T) {
-	db := database.ConnectToDbTest()
-	router := setupRouter(db)
-	truncateCategory(db)
-	tx, _ := db.Begin()
-
-	ctx := context.Background()
-
-	categoryRepository := category.NewCategoryRepository()
-	category := categoryRepository.Save(ctx, tx, model.Category{
-		Name: "Furniture",
-	})
-
-	rdb := redis.InitRedis()
-	productRepository := NewProductRepository(rdb)
-
-	product := productRepository.Save(ctx, tx, product_model.Product{
-		Name:       "Table",
-		CategoryId: category.Id,
-	})
-	tx.Commit()
+	backend := newTestBackend()
+	router := setupRouter(backend)
+	product := backend.fixtures(t).Product().Create()
 
 	t.Run("Test Delete Product Success", func(t *testing.T) {
 		req := httptest.NewRequest("DELETE", "http://localhost:3001/products/"+strconv.Itoa(product.Id), nil)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
 
@@ -258,6 +417,7 @@ func TestDeleteProduct(t *testing.T) {
 	t.Run("Test Delete Product Failed", func(t *testing.T) {
 		req := httptest.NewRequest("DELETE", "http://localhost:3001/products/404", nil)
 		req.Header.Add("Content-Type", "application/json")
+		authorize(req)
 		recorder := httptest.NewRecorder()
 		router.ServeHTTP(recorder, req)
 
@@ -270,3 +430,44 @@ func TestDeleteProduct(t *testing.T) {
 		assert.Equal(t, 404, res.StatusCode)
 	})
 }
+
+func TestSearchProduct(t *testing.T) {
+	backend := newTestBackend()
+	router := setupRouter(backend)
+	fixtures := backend.fixtures(t)
+	furniture := fixtures.Category().Name("Furniture").Create()
+	kitchen := fixtures.Category().Name("Kitchen Table").Create()
+	fixtures.Product().Name("Table").Category(furniture).Create()
+	fixtures.Product().Name("Knife").Category(kitchen).Create()
+	fixtures.Product().Name("Chair").Category(furniture).Create()
+
+	t.Run("Test Search Product Ranks Name Matches First", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "http://localhost:3001/products/search?q=tab", nil)
+		authorize(req)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		res := recorder.Result()
+
+		body, _ := ioutil.ReadAll(res.Body)
+		var responseBody map[string]interface{}
+		json.Unmarshal(body, &responseBody)
+
+		data := responseBody["data"].([]interface{})
+		first := data[0].(map[string]interface{})
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Equal(t, 2, len(data))
+		assert.Equal(t, "Table", first["name"])
+		assert.Equal(t, "<mark>Table</mark>", first["highlights"].(map[string]interface{})["name"])
+		assert.Equal(t, "Knife", data[1].(map[string]interface{})["name"])
+	})
+
+	t.Run("Test Search Product Without Query", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "http://localhost:3001/products/search", nil)
+		authorize(req)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, 400, recorder.Result().StatusCode)
+	})
+}
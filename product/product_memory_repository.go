@@ -0,0 +1,172 @@
+package product
+
+import (
+	"context"
+	"strconv"
+	"task-one/category"
+	"task-one/configs/memory"
+	"task-one/exception"
+	"task-one/helpers"
+	"task-one/product/model"
+)
+
+// ProductMemoryRepository keeps products in a map and reads category names
+// from the category repository of the same memory.Store, as the SQL
+// repository joins them.
+type ProductMemoryRepository struct {
+	products   map[int]model.Product
+	nextId     int
+	categories category.CategoryRepository
+}
+
+func NewProductMemoryRepository(categories *category.CategoryMemoryRepository) ProductRepository {
+	repository := &ProductMemoryRepository{products: map[int]model.Product{}, nextId: 1, categories: categories}
+	categories.ReferencedBy(repository.hasCategory)
+	return repository
+}
+
+func (repository *ProductMemoryRepository) Save(ctx context.Context, tx helpers.Tx, product model.Product) (model.Product, error) {
+	_, err := repository.categories.FindById(ctx, tx, product.CategoryId)
+	if err != nil {
+		return product, exception.NewConflictError("the record is still referenced by or references another record")
+	}
+
+	product.Id = repository.nextId
+	repository.nextId++
+	repository.put(tx, product)
+	return repository.withCategory(ctx, tx, product)
+}
+
+// Update changes the name and category of product where they are set, like
+// the SQL repository.
+func (repository *ProductMemoryRepository) Update(ctx context.Context, tx helpers.Tx, product model.Product) (model.Product, error) {
+	stored, ok := repository.products[product.Id]
+	if !ok || (product.Name == "" && product.CategoryId == 0) {
+		return product, nil
+	}
+
+	if product.Name != "" {
+		stored.Name = product.Name
+	}
+	if product.CategoryId != 0 {
+		_, err := repository.categories.FindById(ctx, tx, product.CategoryId)
+		if err != nil {
+			return product, exception.NewConflictError("the record is still referenced by or references another record")
+		}
+		stored.CategoryId = product.CategoryId
+	}
+	repository.put(tx, stored)
+	return repository.withCategory(ctx, tx, stored)
+}
+
+func (repository *ProductMemoryRepository) Delete(ctx context.Context, tx helpers.Tx, productId int) error {
+	previous, ok := repository.products[productId]
+	if !ok {
+		return nil
+	}
+
+	delete(repository.products, productId)
+	memory.OnRollback(tx, func() { repository.products[productId] = previous })
+	return nil
+}
+
+func (repository *ProductMemoryRepository) FindAll(ctx context.Context, tx helpers.Tx, query helpers.ListQuery) ([]model.Product, helpers.PageInfo, error) {
+	products, err := repository.all(ctx, tx)
+	if err != nil {
+		return nil, helpers.PageInfo{}, err
+	}
+
+	var matching []model.Product
+	for _, product := range products {
+		if categoryId, ok := query.Filters["category_id"]; ok && categoryId != strconv.Itoa(product.CategoryId) {
+			continue
+		}
+		if name, ok := query.Filters["name~"]; ok && !memory.ContainsFold(product.Name, name) {
+			continue
+		}
+		matching = append(matching, product)
+	}
+
+	page, pageInfo := memory.List(matching, query, func(product model.Product, field string) interface{} {
+		switch field {
+		case "name":
+			return product.Name
+		case "category_id":
+			return product.CategoryId
+		case "category_name":
+			return product.CategoryName
+		default:
+			return product.Id
+		}
+	})
+	return page, pageInfo, nil
+}
+
+func (repository *ProductMemoryRepository) FindById(ctx context.Context, tx helpers.Tx, productId int) (model.Product, error) {
+	product, ok := repository.products[productId]
+	if !ok {
+		return product, exception.NewNotFoundError("product Not Found")
+	}
+	return repository.withCategory(ctx, tx, product)
+}
+
+// Search matches products whose name or category name contain every word of
+// search as a word prefix, like the SQL repository, ranked by rankProducts.
+func (repository *ProductMemoryRepository) Search(ctx context.Context, tx helpers.Tx, search string, query helpers.ListQuery) ([]model.ProductSearchResult, helpers.PageInfo, error) {
+	pageInfo := helpers.PageInfo{}
+	terms := searchTerms(search)
+	if len(terms) == 0 {
+		return nil, pageInfo, nil
+	}
+
+	products, err := repository.all(ctx, tx)
+	if err != nil {
+		return nil, pageInfo, err
+	}
+
+	results, pageInfo := rankProducts(products, terms, query)
+	return results, pageInfo, nil
+}
+
+func (repository *ProductMemoryRepository) all(ctx context.Context, tx helpers.Tx) ([]model.Product, error) {
+	var products []model.Product
+	for _, product := range repository.products {
+		product, err := repository.withCategory(ctx, tx, product)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, nil
+}
+
+func (repository *ProductMemoryRepository) withCategory(ctx context.Context, tx helpers.Tx, product model.Product) (model.Product, error) {
+	category, err := repository.categories.FindById(ctx, tx, product.CategoryId)
+	if err != nil {
+		return product, err
+	}
+	product.CategoryName = category.Name
+	return product, nil
+}
+
+func (repository *ProductMemoryRepository) hasCategory(categoryId int) bool {
+	for _, product := range repository.products {
+		if product.CategoryId == categoryId {
+			return true
+		}
+	}
+	return false
+}
+
+// put stores product and registers how to restore what it replaced.
+func (repository *ProductMemoryRepository) put(tx helpers.Tx, product model.Product) {
+	previous, existed := repository.products[product.Id]
+	repository.products[product.Id] = product
+	memory.OnRollback(tx, func() {
+		if existed {
+			repository.products[product.Id] = previous
+		} else {
+			delete(repository.products, product.Id)
+		}
+	})
+}
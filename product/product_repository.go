@@ -2,94 +2,84 @@ package product
 
 import (
 	"context"
-	"database/sql"
-	"encoding/json"
-	"errors"
-	"task-one/configs/redis"
+	"log/slog"
+	"strconv"
+	"strings"
+	"task-one/configs/database"
+	"task-one/configs/tracing"
+	"task-one/exception"
 	"task-one/helpers"
 	"task-one/product/model"
+	"unicode"
 )
 
-type ProductRepository interface {
-	Save(ctx context.Context, tx *sql.Tx, product model.Product) model.Product
-	Update(ctx context.Context, tx *sql.Tx, product model.Product) model.Product
-	Delete(ctx context.Context, tx *sql.Tx, productId int)
-	FindAll(ctx context.Context, tx *sql.Tx) []model.Product
-	FindById(ctx context.Context, tx *sql.Tx, productId int) (model.Product, error)
-	UpdateCache(ctx context.Context, tx *sql.Tx)
-}
+var (
+	ProductSortFields = []string{"id", "name", "category_id", "category_name"}
+	ProductFilters    = []string{"category_id", "name~"}
+)
 
-type ProductRepositoryImpl struct {
-	rdb *redis.RedisClient
+var productColumns = map[string]string{
+	"id":            "product.id",
+	"name":          "product.name",
+	"category_id":   "product.category_id",
+	"category_name": "category.name",
 }
 
-func (p *ProductRepositoryImpl) UpdateCache(ctx context.Context, tx *sql.Tx) {
-	//defer wg.Done()
-
-	query := "SELECT product.id,product.name,category.name FROM product INNER JOIN category ON product.category_id = category.id"
-	rows, err := tx.QueryContext(ctx, query)
-	helpers.PanicIfError(err)
-	defer rows.Close()
-
-	var products []model.Product
-
-	for rows.Next() {
-		product := model.Product{}
-		err := rows.Scan(&product.Id, &product.Name, &product.CategoryName)
-		helpers.PanicIfError(err)
-
-		products = append(products, product)
-	}
+type ProductRepository interface {
+	Save(ctx context.Context, tx helpers.Tx, product model.Product) (model.Product, error)
+	Update(ctx context.Context, tx helpers.Tx, product model.Product) (model.Product, error)
+	Delete(ctx context.Context, tx helpers.Tx, productId int) error
+	FindAll(ctx context.Context, tx helpers.Tx, query helpers.ListQuery) ([]model.Product, helpers.PageInfo, error)
+	FindById(ctx context.Context, tx helpers.Tx, productId int) (model.Product, error)
+	Search(ctx context.Context, tx helpers.Tx, search string, query helpers.ListQuery) ([]model.ProductSearchResult, helpers.PageInfo, error)
+}
 
-	key := "list:products"
-	err = p.rdb.Set(ctx, key, products)
-	helpers.PanicIfError(err)
+type ProductRepositoryImpl struct {
+	Dialect database.Dialect
+	Logger  *slog.Logger
 }
 
-func NewProductRepository(rdb *redis.RedisClient) ProductRepository {
+func NewProductRepository(dialect database.Dialect, logger *slog.Logger) ProductRepository {
 	return &ProductRepositoryImpl{
-		rdb: rdb,
+		Dialect: dialect,
+		Logger:  logger,
 	}
 }
 
-func (p *ProductRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, product model.Product) model.Product {
-	query := `
-		WITH product AS (
-			INSERT INTO product(name, category_id)
-			VALUES($1, $2)
-			RETURNING id, name ,category_id
-		)
-		SELECT product.id,product.name, category.name
-		FROM product
-		INNER JOIN category ON product.category_id = category.id
-	`
-
-	row := tx.QueryRowContext(ctx, query, product.Name, product.CategoryId)
-	err := row.Scan(&product.Id, &product.Name, &product.CategoryName)
-	helpers.PanicIfError(err)
+// Save inserts product, then reads its category name in a second statement:
+// SQLite cannot run an INSERT ... RETURNING inside a WITH clause.
+func (p *ProductRepositoryImpl) Save(ctx context.Context, tx helpers.Tx, product model.Product) (model.Product, error) {
+	query := "INSERT INTO product(name, category_id) VALUES($1, $2) RETURNING id"
+	err := tracing.QueryRowContext(ctx, tx, query, product.Name, product.CategoryId).Scan(&product.Id)
+	if err != nil {
+		return product, err
+	}
 
-	p.UpdateCache(ctx, tx)
-	return product
+	selectQuery := "SELECT product.name, category.name" + productListFrom + " WHERE product.id = $1"
+	err = tracing.QueryRowContext(ctx, tx, selectQuery, product.Id).Scan(&product.Name, &product.CategoryName)
+	return product, err
 }
 
-func (p *ProductRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, product model.Product) model.Product {
+func (p *ProductRepositoryImpl) Update(ctx context.Context, tx helpers.Tx, product model.Product) (model.Product, error) {
 	var query string
 	var err error
 
 	if product.CategoryId != 0 && product.Name != "" {
 		query = "UPDATE product SET name = $1, category_id = $2 WHERE id = $3 RETURNING id, name"
-		err = tx.QueryRowContext(ctx, query, product.Name, product.CategoryId, product.Id).Scan(&product.Id, &product.Name)
+		err = tracing.QueryRowContext(ctx, tx, query, product.Name, product.CategoryId, product.Id).Scan(&product.Id, &product.Name)
 	} else if product.CategoryId != 0 && product.Name == "" {
 		query = "UPDATE product SET category_id = $1 WHERE id = $2 RETURNING id"
-		err = tx.QueryRowContext(ctx, query, product.CategoryId, product.Id).Scan(&product.Id)
+		err = tracing.QueryRowContext(ctx, tx, query, product.CategoryId, product.Id).Scan(&product.Id)
 	} else if product.CategoryId == 0 && product.Name != "" {
 		query = "UPDATE product SET name = $1 WHERE id = $2 RETURNING id, name"
-		err = tx.QueryRowContext(ctx, query, product.Name, product.Id).Scan(&product.Id, &product.Name)
+		err = tracing.QueryRowContext(ctx, tx, query, product.Name, product.Id).Scan(&product.Id, &product.Name)
 	} else {
-		return product
+		return product, nil
 	}
 
-	helpers.PanicIfError(err)
+	if err != nil {
+		return product, err
+	}
 
 	selectQuery := `
 		SELECT p.id, p.name, c.name
@@ -97,62 +87,226 @@ func (p *ProductRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, product
 		INNER JOIN category c ON p.category_id = c.id
 		WHERE p.id = $1
 	`
-	row := tx.QueryRowContext(ctx, selectQuery, product.Id)
+	row := tracing.QueryRowContext(ctx, tx, selectQuery, product.Id)
 	err = row.Scan(&product.Id, &product.Name, &product.CategoryName)
-	helpers.PanicIfError(err)
-
-	p.UpdateCache(ctx, tx)
-	return product
+	return product, err
 }
 
-func (p *ProductRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, productId int) {
+func (p *ProductRepositoryImpl) Delete(ctx context.Context, tx helpers.Tx, productId int) error {
 	query := "DELETE FROM product where id = $1"
-	_, err := tx.ExecContext(ctx, query, productId)
-	helpers.PanicIfError(err)
+	_, err := tracing.ExecContext(ctx, tx, query, productId)
+	return err
+}
 
-	p.UpdateCache(ctx, tx)
+func (p *ProductRepositoryImpl) FindAll(ctx context.Context, tx helpers.Tx, query helpers.ListQuery) ([]model.Product, helpers.PageInfo, error) {
+	p.Logger.DebugContext(ctx, "listing products", slog.String("query", query.CacheKey()))
+	if query.CursorMode {
+		return p.findAfter(ctx, tx, query)
+	}
+	return p.findAll(ctx, tx, query)
 }
 
-func (p *ProductRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) []model.Product {
-	var products []model.Product
-	key := "list:products"
-	productsCache, err := p.rdb.Get(ctx, key)
+func (p *ProductRepositoryImpl) FindById(ctx context.Context, tx helpers.Tx, productId int) (model.Product, error) {
+	query := "SELECT product.id,product.name,product.category_id,category.name FROM product INNER JOIN category ON product.category_id = category.id WHERE product.id = $1"
+	rows, err := tracing.QueryContext(ctx, tx, query, productId)
+	product := model.Product{}
 	if err != nil {
-		query := "SELECT product.id,product.name,category.name FROM product INNER JOIN category ON product.category_id = category.id"
-		rows, err := tx.QueryContext(ctx, query)
-		helpers.PanicIfError(err)
-		defer rows.Close()
+		return product, err
+	}
+	defer rows.Close()
 
-		for rows.Next() {
-			product := model.Product{}
-			err := rows.Scan(&product.Id, &product.Name, &product.CategoryName)
-			helpers.PanicIfError(err)
+	if rows.Next() {
+		err := rows.Scan(&product.Id, &product.Name, &product.CategoryId, &product.CategoryName)
+		return product, err
+	} else {
+		return product, exception.NewNotFoundError("product Not Found")
+	}
+}
 
-			products = append(products, product)
+// Search ranks products whose name or category name match every word of
+// search, each word also matching as a prefix so partial input finds results
+// while the user is still typing. It reads the search_vector column kept up to
+// date by triggers, which weighs the product name above the category name.
+func (p *ProductRepositoryImpl) Search(ctx context.Context, tx helpers.Tx, search string, query helpers.ListQuery) ([]model.ProductSearchResult, helpers.PageInfo, error) {
+	if !p.Dialect.FullTextSearch() {
+		return p.searchByLike(ctx, tx, search, query)
+	}
+
+	pageInfo := helpers.PageInfo{}
+	tsQuery := prefixTsQuery(search)
+	if tsQuery == "" {
+		return nil, pageInfo, nil
+	}
+
+	from := productListFrom + ", to_tsquery('simple', $1) search WHERE product.search_vector @@ search"
+	err := tracing.QueryRowContext(ctx, tx, "SELECT COUNT(*)"+from, tsQuery).Scan(&pageInfo.Total)
+	if err != nil {
+		return nil, pageInfo, err
+	}
+
+	selectQuery := `
+		SELECT product.id, product.name, product.category_id, category.name,
+			ts_rank(product.search_vector, search) AS score,
+			ts_headline('simple', product.name, search, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+			ts_headline('simple', category.name, search, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')
+	` + from + " ORDER BY score DESC, product.id LIMIT $2 OFFSET $3"
+	rows, err := tracing.QueryContext(ctx, tx, selectQuery, tsQuery, query.Limit, query.Offset())
+	if err != nil {
+		return nil, pageInfo, err
+	}
+	defer rows.Close()
+
+	var results []model.ProductSearchResult
+
+	for rows.Next() {
+		result := model.ProductSearchResult{}
+		err := rows.Scan(&result.Id, &result.Name, &result.CategoryId, &result.CategoryName,
+			&result.Score, &result.NameHighlight, &result.CategoryNameHighlight)
+		if err != nil {
+			return nil, pageInfo, err
 		}
 
-		err = p.rdb.Set(ctx, key, products)
-		helpers.PanicIfError(err)
-		return products
+		results = append(results, result)
+	}
+
+	return results, pageInfo, rows.Err()
+}
+
+// searchByLike serves Search without search_vector. The rows holding every
+// word anywhere in the product or category name are read with LIKE, then
+// rankProducts keeps the word prefix matches and ranks them. Every candidate is
+// read before paging, which suits the small catalogs of the SQLite sites.
+func (p *ProductRepositoryImpl) searchByLike(ctx context.Context, tx helpers.Tx, search string, query helpers.ListQuery) ([]model.ProductSearchResult, helpers.PageInfo, error) {
+	terms := searchTerms(search)
+	if len(terms) == 0 {
+		return nil, helpers.PageInfo{}, nil
 	}
 
-	err = json.Unmarshal([]byte(productsCache), &products)
-	helpers.PanicIfError(err)
-	return products
+	var conditions []string
+	var args []interface{}
+	for _, term := range terms {
+		args = append(args, helpers.ContainsPattern(term))
+		placeholder := "$" + strconv.Itoa(len(args))
+		conditions = append(conditions, "("+p.Dialect.ILike("product.name", placeholder)+" OR "+p.Dialect.ILike("category.name", placeholder)+")")
+	}
+
+	selectQuery := "SELECT product.id,product.name,product.category_id,category.name" + productListFrom + helpers.WhereClause(conditions)
+	products, err := p.findProducts(ctx, tx, selectQuery, args...)
+	if err != nil {
+		return nil, helpers.PageInfo{}, err
+	}
+
+	results, pageInfo := rankProducts(products, terms, query)
+	return results, pageInfo, nil
 }
 
-func (p *ProductRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, productId int) (model.Product, error) {
-	query := "SELECT product.id,product.name,category.name FROM product INNER JOIN category ON product.category_id = category.id WHERE product.id = $1"
-	rows, err := tx.QueryContext(ctx, query, productId)
-	helpers.PanicIfError(err)
+const productListFrom = " FROM product INNER JOIN category ON product.category_id = category.id"
+
+func (p *ProductRepositoryImpl) findAll(ctx context.Context, tx helpers.Tx, query helpers.ListQuery) ([]model.Product, helpers.PageInfo, error) {
+	pageInfo := helpers.PageInfo{}
+	conditions, args := productConditions(p.Dialect, query)
+	where := helpers.WhereClause(conditions)
+
+	err := tracing.QueryRowContext(ctx, tx, "SELECT COUNT(*)"+productListFrom+where, args...).Scan(&pageInfo.Total)
+	if err != nil {
+		return nil, pageInfo, err
+	}
+
+	selectQuery := "SELECT product.id,product.name,product.category_id,category.name" + productListFrom + where + query.OrderBy(productColumns) +
+		" LIMIT $" + strconv.Itoa(len(args)+1) + " OFFSET $" + strconv.Itoa(len(args)+2)
+	products, err := p.findProducts(ctx, tx, selectQuery, append(args, query.Limit, query.Offset())...)
+	return products, pageInfo, err
+}
+
+// findAfter reads one keyset page with a row comparison against the cursor,
+// so walking deep into the table costs the same as reading the first page.
+// One extra row is fetched to learn whether a next page exists.
+func (p *ProductRepositoryImpl) findAfter(ctx context.Context, tx helpers.Tx, query helpers.ListQuery) ([]model.Product, helpers.PageInfo, error) {
+	pageInfo := helpers.PageInfo{}
+	conditions, args := productConditions(p.Dialect, query)
+	if query.Cursor != nil {
+		condition, cursorArgs := query.KeysetCondition(productColumns, len(args))
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
+	}
+
+	selectQuery := "SELECT product.id,product.name,product.category_id,category.name" + productListFrom +
+		helpers.WhereClause(conditions) + query.OrderBy(productColumns) + " LIMIT $" + strconv.Itoa(len(args)+1)
+	products, err := p.findProducts(ctx, tx, selectQuery, append(args, query.Limit+1)...)
+	if err != nil {
+		return nil, pageInfo, err
+	}
+
+	if len(products) > query.Limit {
+		products = products[:query.Limit]
+		last := products[len(products)-1]
+		pageInfo.NextCursor = query.NextCursor(productSortValue(last, query.Sort[0].Field), last.Id)
+	}
+	return products, pageInfo, nil
+}
+
+func (p *ProductRepositoryImpl) findProducts(ctx context.Context, tx helpers.Tx, selectQuery string, args ...interface{}) ([]model.Product, error) {
+	rows, err := tracing.QueryContext(ctx, tx, selectQuery, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
-	product := model.Product{}
-	if rows.Next() {
-		err := rows.Scan(&product.Id, &product.Name, &product.CategoryName)
-		helpers.PanicIfError(err)
-		return product, nil
-	} else {
-		return product, errors.New("product Not Found")
+	var products []model.Product
+
+	for rows.Next() {
+		product := model.Product{}
+		err := rows.Scan(&product.Id, &product.Name, &product.CategoryId, &product.CategoryName)
+		if err != nil {
+			return nil, err
+		}
+
+		products = append(products, product)
+	}
+
+	return products, rows.Err()
+}
+
+func productConditions(dialect database.Dialect, query helpers.ListQuery) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if categoryId, ok := query.Filters["category_id"]; ok {
+		args = append(args, categoryId)
+		conditions = append(conditions, "product.category_id = $"+strconv.Itoa(len(args)))
+	}
+	if name, ok := query.Filters["name~"]; ok {
+		args = append(args, helpers.ContainsPattern(name))
+		conditions = append(conditions, dialect.ILike("product.name", "$"+strconv.Itoa(len(args))))
+	}
+
+	return conditions, args
+}
+
+func productSortValue(product model.Product, field string) string {
+	switch field {
+	case "name":
+		return product.Name
+	case "category_id":
+		return strconv.Itoa(product.CategoryId)
+	case "category_name":
+		return product.CategoryName
+	default:
+		return strconv.Itoa(product.Id)
+	}
+}
+
+// prefixTsQuery turns free text into a tsquery requiring every word as a
+// prefix, e.g. "meja kay" becomes "meja:* & kay:*". Everything but letters and
+// digits is dropped, so user input can never inject tsquery operators.
+func prefixTsQuery(search string) string {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var terms []string
+	for _, word := range words {
+		terms = append(terms, word+":*")
 	}
+	return strings.Join(terms, " & ")
 }
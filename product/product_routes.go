@@ -1,27 +1,37 @@
 package product
 
 import (
-	"database/sql"
 	"github.com/julienschmidt/httprouter"
+	"log/slog"
+	"net/http"
 	"sync"
+	"task-one/auth"
 	"task-one/category"
 	"task-one/configs/mail"
-	"task-one/configs/redis"
+	"task-one/configs/ratelimit"
+	"task-one/helpers"
 )
 
-func RegisterRoute(router *httprouter.Router, db *sql.DB) {
-	rdb := redis.InitRedis()
+func RegisterRoute(router *httprouter.Router, txManager helpers.TxManager, productRepository ProductRepository, categoryRepository category.CategoryRepository, mailer mail.Mailer, authMiddleware auth.AuthMiddleware, limiter ratelimit.Middleware, principalLimiter ratelimit.Middleware, logger *slog.Logger) {
 	wg := new(sync.WaitGroup)
-	smtp := &mail.SMTPMailer{}
 
-	productRepository := NewProductRepository(rdb)
-	categoryRepository := category.NewCategoryRepository()
-	productService := NewProductService(productRepository, db, categoryRepository, wg, smtp)
-	productController := NewProductController(productService)
+	productService := NewProductService(productRepository, txManager, categoryRepository, wg, mailer, logger)
+	productController := NewProductController(productService, logger)
 
-	router.GET("/products", productController.FindAll)
-	router.GET("/products/:id", productController.FindById)
-	router.PATCH("/products/:id", productController.Update)
-	router.POST("/products", productController.Create)
-	router.DELETE("/products/:id", productController.Delete)
+	// Reads are public, as they have always been; only writes need a token.
+	router.GET("/products", limiter.Limit("GET /products", productController.FindAll))
+	// httprouter cannot register /products/search next to /products/:id, so
+	// the search is dispatched from the :id route.
+	search := limiter.Limit("GET /products/search", productController.Search)
+	findById := limiter.Limit("GET /products/:id", productController.FindById)
+	router.GET("/products/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		if params.ByName("id") == "search" {
+			search(writer, request, params)
+			return
+		}
+		findById(writer, request, params)
+	})
+	router.PATCH("/products/:id", limiter.Limit("PATCH /products/:id", authMiddleware.Authorize(auth.ProductsWrite, principalLimiter.Limit("PATCH /products/:id", productController.Update))))
+	router.POST("/products", limiter.Limit("POST /products", authMiddleware.Authorize(auth.ProductsWrite, principalLimiter.Limit("POST /products", productController.Create))))
+	router.DELETE("/products/:id", limiter.Limit("DELETE /products/:id", authMiddleware.Authorize(auth.ProductsWrite, principalLimiter.Limit("DELETE /products/:id", productController.Delete))))
 }
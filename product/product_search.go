@@ -0,0 +1,113 @@
+package product
+
+import (
+	"sort"
+	"strings"
+	"task-one/helpers"
+	"task-one/product/model"
+	"unicode"
+)
+
+// rankProducts ranks the products matching every term as a word prefix, for
+// the backends without Postgres full-text search, and returns the page of
+// query. The score only mimics ts_rank: a term found in the name counts 1, one
+// found in the category name 0.4, averaged over the terms.
+func rankProducts(products []model.Product, terms []string, query helpers.ListQuery) ([]model.ProductSearchResult, helpers.PageInfo) {
+	var results []model.ProductSearchResult
+	for _, product := range products {
+		nameWords := searchTerms(product.Name)
+		categoryWords := searchTerms(product.CategoryName)
+
+		score := 0.0
+		for _, term := range terms {
+			if hasPrefixedWord(nameWords, term) {
+				score += 1
+			} else if hasPrefixedWord(categoryWords, term) {
+				score += 0.4
+			} else {
+				score = -1
+				break
+			}
+		}
+		if score < 0 {
+			continue
+		}
+
+		results = append(results, model.ProductSearchResult{
+			Product:               product,
+			Score:                 score / float64(len(terms)),
+			NameHighlight:         highlight(product.Name, terms),
+			CategoryNameHighlight: highlight(product.CategoryName, terms),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Id < results[j].Id
+	})
+
+	pageInfo := helpers.PageInfo{Total: len(results)}
+	start := query.Offset()
+	if start > len(results) {
+		start = len(results)
+	}
+	end := start + query.Limit
+	if end > len(results) {
+		end = len(results)
+	}
+	return results[start:end], pageInfo
+}
+
+// searchTerms splits text into lower case words the way prefixTsQuery does.
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func hasPrefixedWord(words []string, term string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight wraps every word of text starting with one of terms in <mark>
+// tags, as ts_headline does with HighlightAll.
+func highlight(text string, terms []string) string {
+	var builder strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) && !unicode.IsDigit(runes[i]) {
+			builder.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+			end++
+		}
+		word := string(runes[i:end])
+		if matchesTerm(strings.ToLower(word), terms) {
+			builder.WriteString("<mark>" + word + "</mark>")
+		} else {
+			builder.WriteString(word)
+		}
+		i = end
+	}
+	return builder.String()
+}
+
+func matchesTerm(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,11 +2,11 @@ package product
 
 import (
 	"context"
-	"database/sql"
+	"log/slog"
 	"sync"
 	"task-one/category"
 	"task-one/configs/mail"
-	"task-one/exception"
+	"task-one/configs/tracing"
 	"task-one/helpers"
 	"task-one/product/dto"
 	"task-one/product/model"
@@ -14,44 +14,54 @@ import (
 )
 
 type ProductService interface {
-	Create(ctx context.Context, request *dto.ProductCreateDto) response.ProductResponse
-	Update(ctx context.Context, request *dto.ProductUpdateDto) response.ProductResponse
-	Delete(ctx context.Context, productId int)
-	FindById(ctx context.Context, productId int) response.ProductResponse
-	FindAll(ctx context.Context) []response.ProductResponse
+	Create(ctx context.Context, request *dto.ProductCreateDto) (response.ProductResponse, error)
+	Update(ctx context.Context, request *dto.ProductUpdateDto) (response.ProductResponse, error)
+	Delete(ctx context.Context, productId int) error
+	FindById(ctx context.Context, productId int) (response.ProductResponse, error)
+	FindAll(ctx context.Context, query helpers.ListQuery) ([]response.ProductResponse, helpers.PageInfo, error)
+	Search(ctx context.Context, search string, query helpers.ListQuery) ([]response.ProductSearchResponse, helpers.PageInfo, error)
 }
 
 type ProductServiceImpl struct {
 	Repository         ProductRepository
-	DB                 *sql.DB
+	TxManager          helpers.TxManager
 	CategoryRepository category.CategoryRepository
 	Wg                 *sync.WaitGroup
 	Smtp               mail.Mailer
+	Logger             *slog.Logger
 }
 
-func NewProductService(repository ProductRepository, DB *sql.DB, categoryRepository category.CategoryRepository, wg *sync.WaitGroup, smtp mail.Mailer) ProductService {
-	return &ProductServiceImpl{Repository: repository, DB: DB, CategoryRepository: categoryRepository, Wg: wg, Smtp: smtp}
+type productResult struct {
+	Product model.Product
+	Error   error
 }
 
-func (service *ProductServiceImpl) Create(ctx context.Context, request *dto.ProductCreateDto) response.ProductResponse {
-	tx, err := service.DB.Begin()
-	helpers.PanicIfError(err)
-	defer helpers.CommitOrRollback(tx)
+type productsResult struct {
+	Products []model.Product
+	PageInfo helpers.PageInfo
+	Error    error
+}
 
-	_, err = service.CategoryRepository.FindById(ctx, tx, request.CategoryId)
+func NewProductService(repository ProductRepository, txManager helpers.TxManager, categoryRepository category.CategoryRepository, wg *sync.WaitGroup, smtp mail.Mailer, logger *slog.Logger) ProductService {
+	return &ProductServiceImpl{Repository: repository, TxManager: txManager, CategoryRepository: categoryRepository, Wg: wg, Smtp: smtp, Logger: logger}
+}
+
+func (service *ProductServiceImpl) Create(ctx context.Context, request *dto.ProductCreateDto) (productResponse response.ProductResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ProductService.Create")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := service.TxManager.Begin(ctx)
 	if err != nil {
-		panic(exception.NewNotFoundError(err.Error()))
+		return productResponse, err
 	}
+	defer helpers.CommitOrRollback(tx, &err)
 
-	go func() {
-		to := []string{"fazrul.anugrah17@gmail.com", "fazrulsahi@gmail.com"}
-		cc := []string{"tralalala@gmail.com"}
-		subject := "Terbaru Asli!"
-		message := "Hello, Kamu! Kami baru saja meluncurkan Produk baru loh! "
-		service.Smtp.SendMail(to, cc, subject, message)
-	}()
+	_, err = service.CategoryRepository.FindById(ctx, tx, request.CategoryId)
+	if err != nil {
+		return productResponse, err
+	}
 
-	productChannel := make(chan model.Product)
+	productChannel := make(chan productResult)
 	defer close(productChannel)
 	product := model.Product{
 		Name:       request.Name,
@@ -61,32 +71,51 @@ func (service *ProductServiceImpl) Create(ctx context.Context, request *dto.Prod
 	service.Wg.Add(1)
 	go func() {
 		defer service.Wg.Done()
-		product = service.Repository.Save(ctx, tx, product)
-		productChannel <- product
+		product, err := service.Repository.Save(ctx, tx, product)
+		productChannel <- productResult{product, err}
 	}()
 
-	product = <-productChannel
+	result := <-productChannel
 
 	defer service.Wg.Wait()
-	return model.ToProductResponse(product)
+	if result.Error != nil {
+		return productResponse, result.Error
+	}
+
+	// The announcement only goes out for a product that was committed.
+	tx.AfterCommit(func() {
+		to := []string{"fazrul.anugrah17@gmail.com", "fazrulsahi@gmail.com"}
+		cc := []string{"tralalala@gmail.com"}
+		subject := "Terbaru Asli!"
+		message := "Hello, Kamu! Kami baru saja meluncurkan Produk baru loh! "
+		service.Smtp.SendMail(ctx, to, cc, subject, message)
+	})
+
+	service.Logger.InfoContext(ctx, "product created", slog.Int("product_id", result.Product.Id))
+	return model.ToProductResponse(result.Product), nil
 }
 
-func (service *ProductServiceImpl) Update(ctx context.Context, request *dto.ProductUpdateDto) response.ProductResponse {
-	tx, err := service.DB.Begin()
-	helpers.PanicIfError(err)
-	defer helpers.CommitOrRollback(tx)
+func (service *ProductServiceImpl) Update(ctx context.Context, request *dto.ProductUpdateDto) (productResponse response.ProductResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ProductService.Update")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return productResponse, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
 
 	var product model.Product
 
 	product, err = service.Repository.FindById(ctx, tx, request.Id)
 	if err != nil {
-		panic(exception.NewNotFoundError(err.Error()))
+		return productResponse, err
 	}
 
 	if request.CategoryId != 0 {
 		_, err := service.CategoryRepository.FindById(ctx, tx, request.CategoryId)
 		if err != nil {
-			panic(exception.NewNotFoundError(err.Error()))
+			return productResponse, err
 		}
 		product = model.Product{
 			Id:         request.Id,
@@ -100,86 +129,126 @@ func (service *ProductServiceImpl) Update(ctx context.Context, request *dto.Prod
 		}
 	}
 
-	productChannel := make(chan model.Product)
+	productChannel := make(chan productResult)
 	defer close(productChannel)
 	service.Wg.Add(1)
 	go func() {
 		defer service.Wg.Done()
-		product = service.Repository.Update(ctx, tx, product)
-		productChannel <- product
+		product, err := service.Repository.Update(ctx, tx, product)
+		productChannel <- productResult{product, err}
 	}()
 
-	product = <-productChannel
+	result := <-productChannel
 	defer service.Wg.Wait()
-	return model.ToProductResponse(product)
+	if result.Error != nil {
+		return productResponse, result.Error
+	}
+
+	service.Logger.InfoContext(ctx, "product updated", slog.Int("product_id", result.Product.Id))
+	return model.ToProductResponse(result.Product), nil
 
 }
 
-func (service *ProductServiceImpl) Delete(ctx context.Context, productId int) {
-	tx, err := service.DB.Begin()
-	helpers.PanicIfError(err)
-	defer helpers.CommitOrRollback(tx)
+func (service *ProductServiceImpl) Delete(ctx context.Context, productId int) (err error) {
+	ctx, span := tracing.Start(ctx, "ProductService.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
 
 	product, err := service.Repository.FindById(ctx, tx, productId)
 	if err != nil {
-		panic(exception.NewNotFoundError(err.Error()))
+		return err
 	}
 
 	service.Wg.Add(1)
 	go func() {
 		defer service.Wg.Done()
-		service.Repository.Delete(ctx, tx, product.Id)
+		err = service.Repository.Delete(ctx, tx, product.Id)
 	}()
 
 	service.Wg.Wait()
+	if err != nil {
+		return err
+	}
+
+	service.Logger.InfoContext(ctx, "product deleted", slog.Int("product_id", product.Id))
+	return nil
 }
 
-func (service *ProductServiceImpl) FindById(ctx context.Context, productId int) response.ProductResponse {
-	tx, err := service.DB.Begin()
-	helpers.PanicIfError(err)
-	defer helpers.CommitOrRollback(tx)
+func (service *ProductServiceImpl) FindById(ctx context.Context, productId int) (productResponse response.ProductResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ProductService.FindById")
+	defer func() { tracing.End(span, err) }()
 
-	productChannel := make(chan struct {
-		Product model.Product
-		Error   error
-	})
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return productResponse, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
+
+	productChannel := make(chan productResult)
 	service.Wg.Add(1)
 	defer close(productChannel)
 
 	go func() {
 		defer service.Wg.Done()
 		product, err := service.Repository.FindById(ctx, tx, productId)
-		productChannel <- struct {
-			Product model.Product
-			Error   error
-		}{product, err}
+		productChannel <- productResult{product, err}
 	}()
 
-	productResult := <-productChannel
-	if productResult.Error != nil {
-		panic(exception.NewNotFoundError(productResult.Error.Error()))
+	result := <-productChannel
+	defer service.Wg.Wait()
+	if result.Error != nil {
+		return productResponse, result.Error
 	}
 
-	defer service.Wg.Wait()
-	return model.ToProductResponse(productResult.Product)
+	return model.ToProductResponse(result.Product), nil
 }
 
-func (service *ProductServiceImpl) FindAll(ctx context.Context) []response.ProductResponse {
-	tx, err := service.DB.Begin()
-	helpers.PanicIfError(err)
-	defer helpers.CommitOrRollback(tx)
+func (service *ProductServiceImpl) FindAll(ctx context.Context, query helpers.ListQuery) (productResponses []response.ProductResponse, pageInfo helpers.PageInfo, err error) {
+	ctx, span := tracing.Start(ctx, "ProductService.FindAll")
+	defer func() { tracing.End(span, err) }()
 
-	productsChannel := make(chan []model.Product)
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return nil, pageInfo, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
+
+	productsChannel := make(chan productsResult)
 	defer close(productsChannel)
 	service.Wg.Add(1)
 
 	go func() {
 		defer service.Wg.Done()
-		products := service.Repository.FindAll(ctx, tx)
-		productsChannel <- products
+		products, pageInfo, err := service.Repository.FindAll(ctx, tx, query)
+		productsChannel <- productsResult{products, pageInfo, err}
 	}()
 
-	products := <-productsChannel
+	result := <-productsChannel
 	defer service.Wg.Wait()
-	return model.ToProductResponses(products)
+	if result.Error != nil {
+		return nil, pageInfo, result.Error
+	}
+	return model.ToProductResponses(result.Products), result.PageInfo, nil
+}
+
+func (service *ProductServiceImpl) Search(ctx context.Context, search string, query helpers.ListQuery) (searchResponses []response.ProductSearchResponse, pageInfo helpers.PageInfo, err error) {
+	ctx, span := tracing.Start(ctx, "ProductService.Search")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := service.TxManager.Begin(ctx)
+	if err != nil {
+		return nil, pageInfo, err
+	}
+	defer helpers.CommitOrRollback(tx, &err)
+
+	results, pageInfo, err := service.Repository.Search(ctx, tx, search, query)
+	if err != nil {
+		return nil, pageInfo, err
+	}
+	return model.ToProductSearchResponses(results), pageInfo, nil
 }
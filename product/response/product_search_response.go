@@ -0,0 +1,14 @@
+package response
+
+// ProductSearchResponse is a product matched by a search, with its relevance
+// score and the matched fragments wrapped in <mark> tags.
+type ProductSearchResponse struct {
+	ProductResponse
+	Score      float64           `json:"score"`
+	Highlights ProductHighlights `json:"highlights"`
+}
+
+type ProductHighlights struct {
+	Name         string `json:"name"`
+	CategoryName string `json:"category_name"`
+}
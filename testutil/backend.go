@@ -0,0 +1,41 @@
+package testutil
+
+import (
+	"task-one/configs/database"
+	"task-one/configs/memory"
+	"task-one/helpers"
+)
+
+// Backend is the storage a test runs against, picked by DB_DRIVER: the
+// in-memory store for "memory", the database from DB_TEST_URI otherwise,
+// SQLite in memory unless configured. The repositories over it are built by
+// each package's tests, as testutil cannot import them.
+type Backend struct {
+	TxManager helpers.TxManager
+	// Dialect is nil on the in-memory store.
+	Dialect database.Dialect
+}
+
+// NewBackend returns a fresh in-memory store, or connects to the test
+// database and empties tables, listed referencing tables first. Either way
+// the test starts out with no rows.
+func NewBackend(config *helpers.DBConfig, tables ...string) Backend {
+	if config.Connection == memory.Driver {
+		return Backend{TxManager: memory.NewStore()}
+	}
+
+	db, dialect := database.ConnectToDbTest(config)
+	db.Exec(dialect.Truncate(tables...))
+	return Backend{TxManager: database.NewTxManager(db, dialect), Dialect: dialect}
+}
+
+// InMemory tells whether the repositories should be the in-memory ones.
+func (backend Backend) InMemory() bool {
+	return backend.Dialect == nil
+}
+
+// Serial tells whether the backend runs one transaction at a time, which
+// only Postgres does not.
+func (backend Backend) Serial() bool {
+	return backend.InMemory() || backend.Dialect.Name() != database.Postgres.Name()
+}
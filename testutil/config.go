@@ -0,0 +1,30 @@
+package testutil
+
+import (
+	"fmt"
+	"task-one/configs/memory"
+	"task-one/helpers"
+)
+
+// Config loads the configuration of the tests. It starts from the usual
+// defaults but with a test JWT secret and SQLite, so that a machine without
+// a .env runs the whole suite on an in-memory database. A configured
+// DB_DRIVER takes over; Postgres then needs DB_TEST_URI, as the tests
+// truncate its tables.
+func Config() *helpers.Config {
+	defaults := helpers.DefaultConfig()
+	defaults.DB.Connection = "sqlite"
+	defaults.DB.URI = ":memory:"
+	defaults.JWT.Secret = "test-secret"
+
+	config, _, err := helpers.LoadConfigFrom(defaults, nil)
+	helpers.PanicIfError(err)
+
+	if config.DB.Test_URI == "" && config.DB.Connection != memory.Driver {
+		if config.DB.Connection != "sqlite" {
+			panic(fmt.Sprintf("DB_DRIVER is %s but DB_TEST_URI is not set; set it or unset DB_DRIVER to test on SQLite", config.DB.Connection))
+		}
+		config.DB.Test_URI = ":memory:"
+	}
+	return config
+}
@@ -0,0 +1,113 @@
+package testutil
+
+import (
+	"context"
+	"strconv"
+	category_model "task-one/category/model"
+	"task-one/helpers"
+	product_model "task-one/product/model"
+	"testing"
+)
+
+// CategorySaver and ProductSaver are the parts of the category and product
+// repositories the fixtures need. testutil cannot import those packages, whose
+// own tests use it.
+type CategorySaver interface {
+	Save(ctx context.Context, tx helpers.Tx, category category_model.Category) (category_model.Category, error)
+}
+
+type ProductSaver interface {
+	Save(ctx context.Context, tx helpers.Tx, product product_model.Product) (product_model.Product, error)
+}
+
+// Fixtures seeds records through the repositories under test. Every record is
+// saved and committed in a transaction of its own, and a failure ends the test.
+type Fixtures struct {
+	T          testing.TB
+	TxManager  helpers.TxManager
+	Categories CategorySaver
+	Products   ProductSaver
+	count      int
+}
+
+type CategoryBuilder struct {
+	fixtures *Fixtures
+	category category_model.Category
+}
+
+// Category starts a category with a unique name.
+func (fixtures *Fixtures) Category() *CategoryBuilder {
+	fixtures.count++
+	return &CategoryBuilder{
+		fixtures: fixtures,
+		category: category_model.Category{Name: "Category " + strconv.Itoa(fixtures.count)},
+	}
+}
+
+func (builder *CategoryBuilder) Name(name string) *CategoryBuilder {
+	builder.category.Name = name
+	return builder
+}
+
+func (builder *CategoryBuilder) Create() category_model.Category {
+	builder.fixtures.T.Helper()
+	category := builder.category
+	builder.fixtures.inTx(func(ctx context.Context, tx helpers.Tx) (err error) {
+		category, err = builder.fixtures.Categories.Save(ctx, tx, category)
+		return err
+	})
+	return category
+}
+
+type ProductBuilder struct {
+	fixtures *Fixtures
+	product  product_model.Product
+}
+
+// Product starts a product with a unique name. Unless Category is called, it
+// gets a category of its own on Create.
+func (fixtures *Fixtures) Product() *ProductBuilder {
+	fixtures.count++
+	return &ProductBuilder{
+		fixtures: fixtures,
+		product:  product_model.Product{Name: "Product " + strconv.Itoa(fixtures.count)},
+	}
+}
+
+func (builder *ProductBuilder) Name(name string) *ProductBuilder {
+	builder.product.Name = name
+	return builder
+}
+
+func (builder *ProductBuilder) Category(category category_model.Category) *ProductBuilder {
+	builder.product.CategoryId = category.Id
+	return builder
+}
+
+func (builder *ProductBuilder) Create() product_model.Product {
+	builder.fixtures.T.Helper()
+	product := builder.product
+	if product.CategoryId == 0 {
+		product.CategoryId = builder.fixtures.Category().Create().Id
+	}
+	builder.fixtures.inTx(func(ctx context.Context, tx helpers.Tx) (err error) {
+		product, err = builder.fixtures.Products.Save(ctx, tx, product)
+		return err
+	})
+	return product
+}
+
+func (fixtures *Fixtures) inTx(save func(ctx context.Context, tx helpers.Tx) error) {
+	fixtures.T.Helper()
+	ctx := context.Background()
+	tx, err := fixtures.TxManager.Begin(ctx)
+	if err != nil {
+		fixtures.T.Fatalf("fixtures: begin: %v", err)
+	}
+
+	err = save(ctx, tx)
+	helpers.CommitOrRollback(tx, &err)
+	if err != nil {
+		fixtures.T.Fatalf("fixtures: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+)
+
+type Mail struct {
+	To      []string
+	Cc      []string
+	Subject string
+	Message string
+}
+
+// RecordingMailer is a mail.Mailer keeping every mail instead of sending it.
+// Setting Err makes SendMail fail with it, after recording the mail.
+type RecordingMailer struct {
+	mu    sync.Mutex
+	mails []Mail
+	Err   error
+}
+
+func NewRecordingMailer() *RecordingMailer {
+	return &RecordingMailer{}
+}
+
+func (m *RecordingMailer) SendMail(ctx context.Context, to []string, cc []string, subject, message string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.mails = append(m.mails, Mail{To: to, Cc: cc, Subject: subject, Message: message})
+	return m.Err
+}
+
+// Mails returns the mails sent so far, oldest first.
+func (m *RecordingMailer) Mails() []Mail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Mail(nil), m.mails...)
+}
@@ -0,0 +1,144 @@
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"task-one/configs/redis"
+	"time"
+)
+
+// FakeRedis is an in-process redis.Redis. Values are stored as the JSON that
+// RedisClient would send, and their TTL is kept but never enforced. Setting
+// Err makes every call fail with it, as if the server were down.
+type FakeRedis struct {
+	mu     sync.Mutex
+	values map[string]string
+	ttls   map[string]time.Duration
+	Err    error
+}
+
+func NewFakeRedis() *FakeRedis {
+	return &FakeRedis{values: map[string]string{}, ttls: map[string]time.Duration{}}
+}
+
+func (r *FakeRedis) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	r.values[key] = string(data)
+	r.ttls[key] = ttl
+	return nil
+}
+
+func (r *FakeRedis) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return false, r.Err
+	}
+	if _, exists := r.values[key]; exists {
+		return false, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	r.values[key] = string(data)
+	r.ttls[key] = ttl
+	return true, nil
+}
+
+// Get answers a missing key with redis.Nil, like the real client.
+func (r *FakeRedis) Get(ctx context.Context, key string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return "", r.Err
+	}
+
+	value, ok := r.values[key]
+	if !ok {
+		return "", redis.Nil
+	}
+	return value, nil
+}
+
+func (r *FakeRedis) Delete(ctx context.Context, keys ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+
+	for _, key := range keys {
+		delete(r.values, key)
+		delete(r.ttls, key)
+	}
+	return nil
+}
+
+func (r *FakeRedis) CompareAndDelete(ctx context.Context, key string, value interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if r.values[key] == string(data) {
+		delete(r.values, key)
+		delete(r.ttls, key)
+	}
+	return nil
+}
+
+func (r *FakeRedis) DeleteByPrefix(ctx context.Context, prefix string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+
+	for key := range r.values {
+		if strings.HasPrefix(key, prefix) {
+			delete(r.values, key)
+			delete(r.ttls, key)
+		}
+	}
+	return nil
+}
+
+// TTL is the time to live key was last stored with.
+func (r *FakeRedis) TTL(key string) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.ttls[key]
+}
+
+// Keys lists the stored keys starting with prefix.
+func (r *FakeRedis) Keys(prefix string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var keys []string
+	for key := range r.values {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
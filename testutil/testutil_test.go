@@ -0,0 +1,67 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"github.com/go-playground/assert/v2"
+	"task-one/category"
+	"task-one/configs/memory"
+	"task-one/configs/redis"
+	"task-one/product"
+	"task-one/testutil"
+	"testing"
+	"time"
+)
+
+func TestFakeRedis(t *testing.T) {
+	ctx := context.Background()
+	cache := testutil.NewFakeRedis()
+
+	_, err := cache.Get(ctx, "list:products:a")
+	assert.Equal(t, redis.Nil, err)
+
+	cache.Set(ctx, "list:products:a", map[string]int{"total": 1}, time.Minute)
+	cache.Set(ctx, "list:categories:a", 1, time.Minute)
+	value, err := cache.Get(ctx, "list:products:a")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"total":1}`, value)
+	assert.Equal(t, time.Minute, cache.TTL("list:products:a"))
+
+	cache.DeleteByPrefix(ctx, "list:products:")
+	assert.Equal(t, []string{"list:categories:a"}, cache.Keys("list:"))
+
+	cache.Err = errors.New("connection refused")
+	assert.Equal(t, cache.Err, cache.Set(ctx, "key", 1, 0))
+}
+
+func TestFixtures(t *testing.T) {
+	categories := category.NewCategoryMemoryRepository()
+	fixtures := &testutil.Fixtures{
+		T:          t,
+		TxManager:  memory.NewStore(),
+		Categories: categories,
+		Products:   product.NewProductMemoryRepository(categories),
+	}
+
+	furniture := fixtures.Category().Name("Furniture").Create()
+	table := fixtures.Product().Name("Table").Category(furniture).Create()
+	other := fixtures.Product().Create()
+
+	assert.NotEqual(t, 0, table.Id)
+	assert.Equal(t, "Furniture", table.CategoryName)
+	assert.NotEqual(t, furniture.Id, other.CategoryId)
+	assert.NotEqual(t, "", other.Name)
+}
+
+func TestConfigNeedsTestURIForPostgres(t *testing.T) {
+	t.Setenv("DB_TEST_URI", "")
+	t.Setenv("DB_DRIVER", "sqlite")
+	assert.Equal(t, ":memory:", testutil.Config().DB.Test_URI)
+
+	t.Setenv("DB_DRIVER", "postgres")
+	defer func() {
+		assert.NotEqual(t, nil, recover())
+	}()
+	testutil.Config()
+	t.Fatal("Config fell back to SQLite")
+}